@@ -0,0 +1,241 @@
+package huffman
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mjjs/gompressor/datastructure/vector"
+)
+
+// ErrInvalidFormat is returned when a code table is written in an unknown
+// format.
+var ErrInvalidFormat = errors.New("invalid format")
+
+// Formats of the code table.
+const (
+	FormatText = "text"
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Tree is a prefix tree along with the frequency of each byte in the data it
+// was built from, for inspecting the codes the bytes are encoded into.
+type Tree struct {
+	root *huffmanTreeNode
+}
+
+// Code describes the code a byte is encoded into.
+type Code struct {
+	Symbol    byte   `json:"symbol"`
+	Frequency int    `json:"frequency"`
+	Length    int    `json:"length"`
+	Bits      string `json:"bits"`
+}
+
+// BuildTree builds the prefix tree Compress builds from the data. The tree of
+// empty data has no nodes.
+func BuildTree(data *vector.Vector[byte]) *Tree {
+	if data.Size() == 0 {
+		return &Tree{}
+	}
+
+	return &Tree{root: buildPrefixTree(createFrequencyTable(data))}
+}
+
+// ReadTree reads the prefix tree stored in compressed data. The frequencies of
+// the bytes are not stored, so the data is decompressed to count them.
+func ReadTree(compressed *vector.Vector[byte]) (*Tree, error) {
+	if compressed.Size() == 0 {
+		return &Tree{}, nil
+	}
+
+	data := compressed.Slice()
+
+	if _, err := readLastByteInBits(data); err != nil {
+		return nil, err
+	}
+
+	var seen [256]bool
+
+	root, _, err := decompressPrefixTree(data, 1, 0, &seen)
+	if err != nil {
+		return nil, err
+	}
+
+	decompressed, err := Decompress(compressed)
+	if err != nil {
+		return nil, err
+	}
+
+	frequencies := createFrequencyTable(decompressed)
+	setFrequencies(root, func(b byte) int {
+		frequency, _ := frequencies.Get(b)
+		return frequency
+	})
+
+	return &Tree{root: root}, nil
+}
+
+// setFrequencies sets the frequency of each leaf, and the sum of the
+// frequencies of its leaves to each other node.
+func setFrequencies(node *huffmanTreeNode, frequency func(b byte) int) int {
+	if isLeafNode(node) {
+		node.frequency = frequency(node.value)
+	} else {
+		node.frequency = setFrequencies(node.left, frequency) + setFrequencies(node.right, frequency)
+	}
+
+	return node.frequency
+}
+
+// Codes returns the code of each byte in the tree, ordered by the length of
+// the code and then by the frequency of the byte, most frequent first.
+func (t *Tree) Codes() []Code {
+	var codes []Code
+
+	if t.root == nil {
+		return codes
+	}
+
+	// A tree consisting of only one leaf is encoded with the code 0.
+	if isLeafNode(t.root) {
+		return []Code{{Symbol: t.root.value, Frequency: t.root.frequency, Length: 1, Bits: "0"}}
+	}
+
+	collectCodes(t.root, "", &codes)
+
+	sort.Slice(codes, func(i, j int) bool {
+		if codes[i].Length != codes[j].Length {
+			return codes[i].Length < codes[j].Length
+		}
+
+		if codes[i].Frequency != codes[j].Frequency {
+			return codes[i].Frequency > codes[j].Frequency
+		}
+
+		return codes[i].Symbol < codes[j].Symbol
+	})
+
+	return codes
+}
+
+func collectCodes(node *huffmanTreeNode, bits string, codes *[]Code) {
+	if isLeafNode(node) {
+		*codes = append(*codes, Code{Symbol: node.value, Frequency: node.frequency, Length: len(bits), Bits: bits})
+		return
+	}
+
+	collectCodes(node.left, bits+"0", codes)
+	collectCodes(node.right, bits+"1", codes)
+}
+
+// WriteDOT writes the tree in the Graphviz DOT language. Leaves are labeled
+// with their byte and frequency, other nodes with their frequency, and edges
+// with the bit of the code they stand for.
+func (t *Tree) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("digraph huffman {\n")
+	b.WriteString("  node [shape=circle];\n")
+
+	if t.root != nil {
+		id := 0
+		writeDOTNode(&b, t.root, &id)
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// writeDOTNode writes the node and its children, returning the identifier of
+// the node.
+func writeDOTNode(b *strings.Builder, node *huffmanTreeNode, id *int) int {
+	nodeID := *id
+	*id++
+
+	if isLeafNode(node) {
+		label := fmt.Sprintf("%s\\n%d", dotEscape(SymbolName(node.value)), node.frequency)
+		fmt.Fprintf(b, "  n%d [label=\"%s\", shape=box];\n", nodeID, label)
+
+		return nodeID
+	}
+
+	fmt.Fprintf(b, "  n%d [label=\"%d\"];\n", nodeID, node.frequency)
+
+	left := writeDOTNode(b, node.left, id)
+	fmt.Fprintf(b, "  n%d -> n%d [label=\"0\"];\n", nodeID, left)
+
+	right := writeDOTNode(b, node.right, id)
+	fmt.Fprintf(b, "  n%d -> n%d [label=\"1\"];\n", nodeID, right)
+
+	return nodeID
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// SymbolName returns a readable name of a byte, such as 'a' or '\n'.
+func SymbolName(b byte) string {
+	if b < 0x80 {
+		return strconv.QuoteRune(rune(b))
+	}
+
+	return fmt.Sprintf("0x%02x", b)
+}
+
+// WriteCodeTable writes the codes as a table in the format, which is one of
+// FormatText, FormatCSV and FormatJSON.
+func WriteCodeTable(w io.Writer, codes []Code, format string) error {
+	switch format {
+	case FormatText:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "symbol\tfrequency\tlength\tcode")
+
+		for _, code := range codes {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", SymbolName(code.Symbol), code.Frequency, code.Length, code.Bits)
+		}
+
+		return tw.Flush()
+
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"symbol", "frequency", "length", "code"})
+
+		for _, code := range codes {
+			cw.Write([]string{
+				strconv.Itoa(int(code.Symbol)),
+				strconv.Itoa(code.Frequency),
+				strconv.Itoa(code.Length),
+				code.Bits,
+			})
+		}
+
+		cw.Flush()
+
+		return cw.Error()
+
+	case FormatJSON:
+		if codes == nil {
+			codes = []Code{}
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(codes)
+
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidFormat, format)
+	}
+}
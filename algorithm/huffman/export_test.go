@@ -0,0 +1,178 @@
+package huffman
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mjjs/gompressor/datastructure/vector"
+)
+
+func TestCodes(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []Code
+	}{
+		{input: "", expected: nil},
+		{input: "aaaa", expected: []Code{{Symbol: 'a', Frequency: 4, Length: 1, Bits: "0"}}},
+		{input: "aaaabbc", expected: []Code{
+			{Symbol: 'a', Frequency: 4, Length: 1},
+			{Symbol: 'b', Frequency: 2, Length: 2},
+			{Symbol: 'c', Frequency: 1, Length: 2},
+		}},
+	}
+
+	for _, testCase := range testCases {
+		codes := BuildTree(vector.FromSlice([]byte(testCase.input))).Codes()
+
+		if len(codes) != len(testCase.expected) {
+			t.Errorf("%q: Expected %d codes, got %d", testCase.input, len(testCase.expected), len(codes))
+			continue
+		}
+
+		for i, code := range codes {
+			expected := testCase.expected[i]
+
+			if code.Symbol != expected.Symbol || code.Frequency != expected.Frequency || code.Length != expected.Length {
+				t.Errorf("%q: Expected %+v, got %+v", testCase.input, expected, code)
+			}
+
+			if len(code.Bits) != code.Length {
+				t.Errorf("%q: Expected a code of %d bits, got %s", testCase.input, code.Length, code.Bits)
+			}
+		}
+	}
+}
+
+func TestReadTreeMatchesCompressedData(t *testing.T) {
+	input := vector.FromSlice([]byte("TOBEORNOTTOBEORTOBEORNOT#, this is a somewhat longer input"))
+	compressed := Compress(input)
+
+	tree, err := ReadTree(compressed)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	header, err := ReadHeader(compressed)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	frequencies := map[byte]int{}
+	for _, b := range input.Slice() {
+		frequencies[b]++
+	}
+
+	codes := tree.Codes()
+	if len(codes) != header.Symbols() {
+		t.Errorf("Expected %d codes, got %d", header.Symbols(), len(codes))
+	}
+
+	totalBits := 0
+
+	for _, code := range codes {
+		if code.Length != header.CodeLengths[code.Symbol] {
+			t.Errorf("%s: Expected length %d, got %d", SymbolName(code.Symbol), header.CodeLengths[code.Symbol], code.Length)
+		}
+
+		if code.Frequency != frequencies[code.Symbol] {
+			t.Errorf("%s: Expected frequency %d, got %d", SymbolName(code.Symbol), frequencies[code.Symbol], code.Frequency)
+		}
+
+		totalBits += code.Frequency * code.Length
+	}
+
+	if totalBits != header.CodeBits {
+		t.Errorf("Expected %d code bits, got %d", header.CodeBits, totalBits)
+	}
+}
+
+func TestReadTreeReturnsErrorOnCorruptData(t *testing.T) {
+	if _, err := ReadTree(vector.FromSlice([]byte{9, 1, 'a'})); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected %s, got %v", ErrCorrupt, err)
+	}
+
+	if _, err := ReadTree(vector.FromSlice([]byte{8, 0, 1})); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected %s, got %v", ErrTruncated, err)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	tree := BuildTree(vector.FromSlice([]byte("aaaabbc\"")))
+
+	buf := new(bytes.Buffer)
+	if err := tree.WriteDOT(buf); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	dot := buf.String()
+
+	if !strings.HasPrefix(dot, "digraph huffman {\n") || !strings.HasSuffix(dot, "}\n") {
+		t.Errorf("Expected a digraph, got %s", dot)
+	}
+
+	if leaves := strings.Count(dot, "shape=box"); leaves != 4 {
+		t.Errorf("Expected 4 leaves, got %d", leaves)
+	}
+
+	if edges := strings.Count(dot, "->"); edges != 6 {
+		t.Errorf("Expected 6 edges, got %d", edges)
+	}
+
+	if !strings.Contains(dot, `label="'\"'\n1"`) {
+		t.Errorf("Expected the quote to be escaped, got %s", dot)
+	}
+}
+
+func TestWriteCodeTable(t *testing.T) {
+	codes := BuildTree(vector.FromSlice([]byte("aaaabbc"))).Codes()
+
+	buf := new(bytes.Buffer)
+	if err := WriteCodeTable(buf, codes, FormatCSV); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if len(records) != len(codes)+1 {
+		t.Errorf("Expected %d records, got %d", len(codes)+1, len(records))
+	}
+
+	if expected := []string{"97", "4", "1", codes[0].Bits}; !reflect.DeepEqual(records[1], expected) {
+		t.Errorf("Expected %v, got %v", expected, records[1])
+	}
+
+	buf.Reset()
+	if err := WriteCodeTable(buf, codes, FormatJSON); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	var decoded []Code
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if !reflect.DeepEqual(decoded, codes) {
+		t.Errorf("Expected %v, got %v", codes, decoded)
+	}
+
+	buf.Reset()
+	if err := WriteCodeTable(buf, codes, FormatText); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if lines := strings.Count(buf.String(), "\n"); lines != len(codes)+1 {
+		t.Errorf("Expected %d lines, got %d", len(codes)+1, lines)
+	}
+
+	if err := WriteCodeTable(buf, codes, "xml"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Expected %s, got %v", ErrInvalidFormat, err)
+	}
+}
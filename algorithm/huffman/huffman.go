@@ -3,12 +3,18 @@
 package huffman
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 
+	"github.com/mjjs/gompressor/bitio"
 	"github.com/mjjs/gompressor/datastructure/dictionary"
 	"github.com/mjjs/gompressor/datastructure/priorityqueue"
 	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/limit"
+	"github.com/mjjs/gompressor/progress"
 )
 
 type huffmanTreeNode struct {
@@ -18,76 +24,247 @@ type huffmanTreeNode struct {
 	right     *huffmanTreeNode
 }
 
+// huffmanCode is the code a byte is encoded into. The code consists of the
+// length lowest bits of bits, starting from the most significant one. A 0 bit
+// indicates taking the left child of a node in the prefix tree and 1 the right
+// one. The codes can not grow longer than 64 bits, as that would require an
+// input larger than the Fibonacci number F(65) bytes.
+type huffmanCode struct {
+	bits   uint64
+	length uint
+}
+
 // Compress takes in a vector of uncompressed bytes and outputs a vector of
 // compressed bytes.
-func Compress(uncompressed *vector.Vector) *vector.Vector {
+func Compress(uncompressed *vector.Vector[byte]) *vector.Vector[byte] {
+	// Compression can only fail when the context is done.
+	compressed, _ := CompressContext(context.Background(), uncompressed, nil)
+	return compressed
+}
+
+// CompressContext compresses like Compress, but stops with the error of the
+// context when it is done. The progress of encoding the bytes is reported to
+// report, which may be nil. Empty data is compressed into an empty vector, as
+// it has no prefix tree.
+func CompressContext(ctx context.Context, uncompressed *vector.Vector[byte], report progress.Func) (*vector.Vector[byte], error) {
+	if uncompressed.Size() == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return vector.New[byte](), nil
+	}
+
 	byteFrequencies := createFrequencyTable(uncompressed)
 	prefixTree := buildPrefixTree(byteFrequencies)
 
-	codes := dictionary.New()
-	buildCodes(prefixTree, vector.New(), codes)
+	codes := dictionary.New[byte, huffmanCode](dictionary.IntegerHasher[byte]{})
+	buildCodes(prefixTree, huffmanCode{}, codes)
 
-	compressedPrefixTree := vector.New()
+	compressedPrefixTree := vector.New[byte]()
 	compressPrefixTree(prefixTree, compressedPrefixTree)
 
-	encodedBytes := encodeToHuffmanCodes(uncompressed, codes)
+	compressedCodes := new(bytes.Buffer)
+	writer := bitio.NewBitWriter(compressedCodes, bitio.MSBFirst)
 
-	compressedCodes, lastByteInBits := compressHuffmanCodes(encodedBytes)
+	// Writing into a bytes.Buffer can not fail, so the error is the one of
+	// the context.
+	if err := encodeToHuffmanCodes(ctx, uncompressed, codes, writer, report); err != nil {
+		return nil, err
+	}
 
-	// Reserve space for the last byte size, prefix tree and huffman codes
-	compressed := vector.New(0, 1+uint(compressedPrefixTree.Size()+compressedCodes.Size()))
-	compressed.Append(byte(lastByteInBits))
+	_ = writer.Flush()
 
-	for i := 0; i < compressedPrefixTree.Size(); i++ {
-		compressed.Append(compressedPrefixTree.MustGet(i))
+	lastByteInBits := int(writer.BitsWritten() % 8)
+	if lastByteInBits == 0 {
+		lastByteInBits = 8
 	}
 
-	for i := 0; i < compressedCodes.Size(); i++ {
-		compressed.Append(compressedCodes.MustGet(i))
+	// The bits of the last byte are stored in its least significant bits.
+	if codeBytes := compressedCodes.Bytes(); len(codeBytes) > 0 {
+		codeBytes[len(codeBytes)-1] >>= 8 - lastByteInBits
 	}
 
-	return compressed
+	// Reserve space for the last byte size, prefix tree and huffman codes
+	compressed := vector.New[byte](0, 1+uint(compressedPrefixTree.Size()+compressedCodes.Len()))
+	compressed.Append(byte(lastByteInBits))
+	compressed.Append(compressedPrefixTree.Slice()...)
+	compressed.Append(compressedCodes.Bytes()...)
+
+	report.Report(uncompressed.Size(), compressed.Size())
+
+	return compressed, nil
 }
 
 // Decompress takes in a vector of huffman compressed bytes and outputs a vector
-// of uncompressed bytes. Returns a non-nil error if the decompression fails.
-func Decompress(compressed *vector.Vector) (*vector.Vector, error) {
+// of uncompressed bytes. Returns an error wrapping ErrTruncated or ErrCorrupt if
+// the compressed data is incomplete or malformed.
+func Decompress(compressed *vector.Vector[byte]) (*vector.Vector[byte], error) {
+	return DecompressWithLimits(compressed, limit.Limits{})
+}
+
+// DecompressWithLimits decompresses like Decompress, but stops with an error
+// wrapping limit.ErrExceeded as soon as the decompressed data exceeds the
+// limits.
+func DecompressWithLimits(compressed *vector.Vector[byte], limits limit.Limits) (*vector.Vector[byte], error) {
+	return DecompressContext(context.Background(), compressed, limits)
+}
+
+// DecompressContext decompresses like DecompressWithLimits, but stops with the
+// error of the context when it is done. The context is checked once every
+// progress.Interval decompressed bytes.
+func DecompressContext(ctx context.Context, compressed *vector.Vector[byte], limits limit.Limits) (*vector.Vector[byte], error) {
 	if compressed.Size() == 0 {
 		return compressed, nil
 	}
 
-	lastByteInBits := int(compressed.MustGet(0).(byte))
+	data := compressed.Slice()
 
-	prefixTree, nextIndex := decompressPrefixTree(compressed, 1)
-	decompressed := vector.New()
+	lastByteInBits, err := readLastByteInBits(data)
+	if err != nil {
+		return nil, err
+	}
 
-	codes := decompressHuffmanCodes(compressed, nextIndex, lastByteInBits)
+	var seen [256]bool
 
-	var err error
-	nextIndex = 0
+	prefixTree, nextIndex, err := decompressPrefixTree(data, 1, 0, &seen)
+	if err != nil {
+		return nil, err
+	}
 
-	for nextIndex < codes.Size() {
-		nextIndex, err = decodeHuffmanCode(codes, nextIndex, prefixTree, decompressed)
+	decompressed := vector.New[byte]()
 
-		if err != nil {
+	reader, totalBits := newHuffmanCodeReader(compressed, nextIndex, lastByteInBits)
+
+	for reader.BitsRead() < uint64(totalBits) {
+		if decompressed.Size()%progress.Interval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := limits.Check(len(data), decompressed.Size()+1); err != nil {
 			return nil, err
 		}
+
+		if err := decodeHuffmanCode(reader, prefixTree, decompressed); err != nil {
+			return nil, err
+		}
+	}
+
+	// The last code continued into the unused bits of the last byte.
+	if reader.BitsRead() > uint64(totalBits) {
+		return nil, fmt.Errorf("%w: the last huffman code is incomplete", ErrTruncated)
 	}
 
 	return decompressed, nil
 }
 
+// ErrTruncated is returned when compressed data ends before all of it could
+// be decoded.
+var ErrTruncated = errors.New("truncated data")
+
+// ErrCorrupt is returned when compressed data is malformed.
+var ErrCorrupt = errors.New("corrupt data")
+
+// ErrInvalidPrefixTree is returned when the prefix tree stored in compressed
+// data is malformed. It wraps ErrCorrupt.
+var ErrInvalidPrefixTree = fmt.Errorf("%w: invalid prefix tree", ErrCorrupt)
+
+// MaxExpansion is the maximum number of decompressed bytes per compressed
+// byte. Each byte is encoded into a code of at least one bit.
+const MaxExpansion int = 8
+
+// maxTreeDepth is the depth of the deepest possible prefix tree, which has a
+// leaf for each of the 256 bytes.
+const maxTreeDepth int = 255
+
+// Header is the information stored before the huffman codes in compressed
+// data.
+type Header struct {
+	// CodeLengths maps each byte in the prefix tree into the length of its
+	// code in bits.
+	CodeLengths map[byte]int
+	// CodeBits is the total number of bits in the codes.
+	CodeBits int
+}
+
+// Symbols returns the number of distinct bytes in the prefix tree.
+func (h Header) Symbols() int {
+	return len(h.CodeLengths)
+}
+
+// ReadHeader reads the prefix tree from compressed data without decoding the
+// huffman codes following it. Empty data has an empty header.
+func ReadHeader(compressed *vector.Vector[byte]) (Header, error) {
+	header := Header{CodeLengths: map[byte]int{}}
+
+	if compressed.Size() == 0 {
+		return header, nil
+	}
+
+	data := compressed.Slice()
+
+	lastByteInBits, err := readLastByteInBits(data)
+	if err != nil {
+		return Header{}, err
+	}
+
+	var seen [256]bool
+
+	prefixTree, codesIndex, err := decompressPrefixTree(data, 1, 0, &seen)
+	if err != nil {
+		return Header{}, err
+	}
+
+	// A prefix tree consisting of only one leaf is decoded with the code 0.
+	if isLeafNode(prefixTree) {
+		header.CodeLengths[prefixTree.value] = 1
+	} else {
+		readCodeLengths(prefixTree, 0, header.CodeLengths)
+	}
+
+	if codesIndex < len(data) {
+		header.CodeBits = (len(data)-codesIndex-1)*8 + lastByteInBits
+	}
+
+	return header, nil
+}
+
+// readLastByteInBits reads the number of bits used in the last byte of the
+// huffman codes, which is stored in the first byte of compressed data.
+func readLastByteInBits(data []byte) (int, error) {
+	lastByteInBits := int(data[0])
+	if lastByteInBits < 1 || lastByteInBits > 8 {
+		return 0, fmt.Errorf("%w: invalid number of bits in the last byte: %d", ErrCorrupt, lastByteInBits)
+	}
+
+	return lastByteInBits, nil
+}
+
+// readCodeLengths stores the depth of each leaf in the prefix tree into
+// lengths.
+func readCodeLengths(node *huffmanTreeNode, depth int, lengths map[byte]int) {
+	if isLeafNode(node) {
+		lengths[node.value] = depth
+		return
+	}
+
+	readCodeLengths(node.left, depth+1, lengths)
+	readCodeLengths(node.right, depth+1, lengths)
+}
+
 // createFrequencyTable takes in a vector of bytes and makes a frequency table
 // indicating how often each byte appears in the vector.
-func createFrequencyTable(bytes *vector.Vector) *dictionary.Dictionary {
-	dict := dictionary.New()
+func createFrequencyTable(bytes *vector.Vector[byte]) *dictionary.Dictionary[byte, int] {
+	dict := dictionary.New[byte, int](dictionary.IntegerHasher[byte]{})
 	for i := 0; i < bytes.Size(); i++ {
 		byt := bytes.MustGet(i)
 
 		if frequency, exists := dict.Get(byt); !exists {
 			dict.Set(byt, 1)
 		} else {
-			dict.Set(byt, frequency.(int)+1)
+			dict.Set(byt, frequency+1)
 		}
 	}
 
@@ -97,16 +274,12 @@ func createFrequencyTable(bytes *vector.Vector) *dictionary.Dictionary {
 // buildPrefixTree builds a tree-style data structure from a frequency table.
 // The table priorizes bytes that have a higher frequency. This way the most
 // often used bytes in the data get the shortest codeword when encoding.
-func buildPrefixTree(byteFrequencies *dictionary.Dictionary) *huffmanTreeNode {
-	tree := new(priorityqueue.PriorityQueue)
-	keys := byteFrequencies.Keys()
-
-	for i := 0; i < keys.Size(); i++ {
-		byt := keys.MustGet(i)
-		frequency, _ := byteFrequencies.Get(byt)
+func buildPrefixTree(byteFrequencies *dictionary.Dictionary[byte, int]) *huffmanTreeNode {
+	tree := new(priorityqueue.PriorityQueue[*huffmanTreeNode])
 
-		tree.Enqueue(frequency.(int), &huffmanTreeNode{frequency: frequency.(int), value: byt.(byte)})
-	}
+	byteFrequencies.ForEach(func(byt byte, frequency int) {
+		tree.Enqueue(frequency, &huffmanTreeNode{frequency: frequency, value: byt})
+	})
 
 	for tree.Size() > 1 {
 		aPrio, a := tree.Dequeue()
@@ -114,37 +287,41 @@ func buildPrefixTree(byteFrequencies *dictionary.Dictionary) *huffmanTreeNode {
 
 		newPrio := aPrio + bPrio
 
-		node := &huffmanTreeNode{frequency: newPrio, left: a.(*huffmanTreeNode), right: b.(*huffmanTreeNode)}
+		node := &huffmanTreeNode{frequency: newPrio, left: a, right: b}
 
 		tree.Enqueue(newPrio, node)
 	}
 
 	_, root := tree.Dequeue()
 
-	return root.(*huffmanTreeNode)
+	return root
 }
 
 // buildCodes traverses the prefix tree and builds a code for each unique byte found in
-// the original, uncompressed data. Each code consists of a vector of 0s and 1s.
-// A 0 indicates taking the left child of the current node and 1 the right one.
-// The codes are stored in the result dictionary, which maps each byte to the code.
-func buildCodes(root *huffmanTreeNode, code *vector.Vector, result *dictionary.Dictionary) {
+// the original, uncompressed data. The codes are stored in the result dictionary,
+// which maps each byte to the code. If the tree consists of only one leaf, the
+// byte is given the code 0.
+func buildCodes(root *huffmanTreeNode, code huffmanCode, result *dictionary.Dictionary[byte, huffmanCode]) {
 	if root == nil {
 		return
 	}
 
 	if isLeafNode(root) {
+		if code.length == 0 {
+			code.length = 1
+		}
+
 		result.Set(root.value, code)
 	}
 
-	buildCodes(root.left, code.AppendToCopy(byte(0)), result)
-	buildCodes(root.right, code.AppendToCopy(byte(1)), result)
+	buildCodes(root.left, huffmanCode{bits: code.bits << 1, length: code.length + 1}, result)
+	buildCodes(root.right, huffmanCode{bits: code.bits<<1 | 1, length: code.length + 1}, result)
 }
 
 // compressPrefixTree takes in the root of a prefix tree and the output vector to.
 // Leaf nodes are encoded as byte 1 and the value it represents, other nodes
 // are encoded as byte 0.
-func compressPrefixTree(root *huffmanTreeNode, to *vector.Vector) {
+func compressPrefixTree(root *huffmanTreeNode, to *vector.Vector[byte]) {
 	switch isLeafNode(root) {
 	case true:
 		to.Append(byte(1))
@@ -156,127 +333,141 @@ func compressPrefixTree(root *huffmanTreeNode, to *vector.Vector) {
 	}
 }
 
-// decompressPrefixTree goes through the compressed vector starting from index
-// and recreates the prefix tree from the encoded data.
-func decompressPrefixTree(compressed *vector.Vector, index int) (*huffmanTreeNode, int) {
-	byt := compressed.MustGet(index).(byte)
-	switch byt {
-	case byte(0):
-		left, nextIndex := decompressPrefixTree(compressed, index+1)
-		right, nextIndex := decompressPrefixTree(compressed, nextIndex)
-		return &huffmanTreeNode{left: left, right: right}, nextIndex
-
-	case byte(1):
-		return &huffmanTreeNode{value: compressed.MustGet(index + 1).(byte)}, index + 2
-
-	default:
-		return nil, index + 1
+// decompressPrefixTree goes through the compressed data starting from index
+// and recreates the prefix tree from the encoded data. The index following the
+// tree is returned. seen records the bytes found in the tree so far, as each
+// byte can only have one leaf.
+func decompressPrefixTree(data []byte, index int, depth int, seen *[256]bool) (*huffmanTreeNode, int, error) {
+	if depth > maxTreeDepth {
+		return nil, 0, fmt.Errorf("%w: deeper than %d levels", ErrInvalidPrefixTree, maxTreeDepth)
 	}
-}
 
-// decompressHuffmanCodes takes in compressed bytes and an index where to start
-// decompressing. As all 0/1 bytes have been encoded as bits, lastByteInBits
-// indicates how many bits to read from the last byte.
-func decompressHuffmanCodes(compressed *vector.Vector, index int, lastByteInBits int) *vector.Vector {
-	huffmanCodes := vector.New(0, uint(compressed.Size()-index))
+	if index >= len(data) {
+		return nil, 0, fmt.Errorf("%w: the prefix tree is incomplete", ErrTruncated)
+	}
 
-	for i := index; i < compressed.Size(); i++ {
-		codeByte := compressed.MustGet(i).(byte)
+	switch data[index] {
+	case byte(0):
+		left, nextIndex, err := decompressPrefixTree(data, index+1, depth+1, seen)
+		if err != nil {
+			return nil, 0, err
+		}
 
-		totalBits := 7
-		if i == compressed.Size()-1 {
-			totalBits = lastByteInBits - 1
+		right, nextIndex, err := decompressPrefixTree(data, nextIndex, depth+1, seen)
+		if err != nil {
+			return nil, 0, err
 		}
 
-		for j := totalBits; j >= 0; j-- {
-			huffmanCodes.Append((codeByte >> j) & 1)
+		return &huffmanTreeNode{left: left, right: right}, nextIndex, nil
+
+	case byte(1):
+		if index+1 >= len(data) {
+			return nil, 0, fmt.Errorf("%w: the prefix tree is incomplete", ErrTruncated)
 		}
-	}
 
-	return huffmanCodes
-}
+		value := data[index+1]
+		if seen[value] {
+			return nil, 0, fmt.Errorf("%w: byte %d appears twice", ErrInvalidPrefixTree, value)
+		}
 
-// decodeHuffmanCode reads a huffman code from codes at index and writes it into to.
-// Returns the index where to start reading the next code.
-func decodeHuffmanCode(codes *vector.Vector, index int, root *huffmanTreeNode, to *vector.Vector) (int, error) {
-	if root == nil {
-		return 0, errors.New("No prefix tree supplied")
-	}
+		seen[value] = true
 
-	if isLeafNode(root) {
-		to.Append(root.value)
-		return index, nil
-	}
+		return &huffmanTreeNode{value: value}, index + 2, nil
 
-	next := codes.MustGet(index)
-	switch next {
-	case byte(0):
-		return decodeHuffmanCode(codes, index+1, root.left, to)
-	case byte(1):
-		return decodeHuffmanCode(codes, index+1, root.right, to)
 	default:
-		return 0, fmt.Errorf("An unexpected symbol %x found in the compressed data", next)
+		return nil, 0, fmt.Errorf("%w: unknown node marker %d", ErrInvalidPrefixTree, data[index])
 	}
 }
 
-// encodeToHuffmanCodes goes through each byte in the uncompressed data and returns
-// a vector where each byte has been replaced with the huffman code it represents.
-func encodeToHuffmanCodes(uncompressed *vector.Vector, codes *dictionary.Dictionary) *vector.Vector {
-	encodedHuffmanCodes := vector.New()
+// newHuffmanCodeReader returns a reader for the huffman codes in compressed
+// starting from index, and the total number of bits in the codes. As the
+// codes have been packed into bits, lastByteInBits indicates how many bits
+// to read from the least significant end of the last byte.
+func newHuffmanCodeReader(compressed *vector.Vector[byte], index int, lastByteInBits int) (*bitio.BitReader, int) {
+	if index >= compressed.Size() {
+		return bitio.NewBitReader(bytes.NewReader(nil), bitio.MSBFirst), 0
+	}
 
-	for i := 0; i < uncompressed.Size(); i++ {
-		byt := uncompressed.MustGet(i)
+	codeBytes := compressed.Slice()[index:]
+	lastByte := codeBytes[len(codeBytes)-1] << (8 - lastByteInBits)
 
-		iCode, _ := codes.Get(byt)
-		code := iCode.(*vector.Vector)
+	reader := io.MultiReader(
+		bytes.NewReader(codeBytes[:len(codeBytes)-1]),
+		bytes.NewReader([]byte{lastByte}),
+	)
+
+	return bitio.NewBitReader(reader, bitio.MSBFirst), (len(codeBytes)-1)*8 + lastByteInBits
+}
 
-		for j := 0; j < code.Size(); j++ {
-			encodedHuffmanCodes.Append(code.MustGet(j))
+// decodeHuffmanCode reads a huffman code from reader and writes the byte it
+// represents into to. A prefix tree consisting of only one leaf is decoded
+// with the code 0.
+func decodeHuffmanCode(reader *bitio.BitReader, root *huffmanTreeNode, to *vector.Vector[byte]) error {
+	if isLeafNode(root) {
+		if _, err := reader.ReadBit(); err != nil {
+			return readError(err)
 		}
+
+		to.Append(root.value)
+		return nil
 	}
 
-	return encodedHuffmanCodes
+	return decodeHuffmanCodeFrom(reader, root, to)
 }
 
-// compressHuffmanCodes takes in huffman codes represented as a vector of 1 and 0
-// bytes. Since the bytes can only be 0 and 1, they can be compressed as bits
-// so that 8 bytes can be encoded as one byte.
-func compressHuffmanCodes(codes *vector.Vector) (compressedCodes *vector.Vector, lastByteInBits int) {
-	currentCode := vector.New(0, 8)
-	encodedCode := byte(0)
-	totalBits := 0
-
-	compressedCodes = vector.New()
+func decodeHuffmanCodeFrom(reader *bitio.BitReader, node *huffmanTreeNode, to *vector.Vector[byte]) error {
+	if node == nil {
+		return fmt.Errorf("%w: huffman code leads to a missing node", ErrCorrupt)
+	}
 
-	for i := 0; i < codes.Size(); i++ {
-		currentCode.Append(codes.MustGet(i))
+	if isLeafNode(node) {
+		to.Append(node.value)
+		return nil
+	}
 
-		if currentCode.Size() != 8 && i != codes.Size()-1 {
-			continue
-		}
+	bit, err := reader.ReadBit()
+	if err != nil {
+		return readError(err)
+	}
 
-		for j := 0; j < currentCode.Size(); j++ {
-			totalBits++
+	if bit == 0 {
+		return decodeHuffmanCodeFrom(reader, node.left, to)
+	}
 
-			encodedCode <<= 1
+	return decodeHuffmanCodeFrom(reader, node.right, to)
+}
 
-			if currentCode.MustGet(j) != byte(0) {
-				encodedCode |= 1
+// encodeToHuffmanCodes goes through each byte in the uncompressed data and writes
+// the huffman code it represents into writer. The context is checked and the
+// progress reported once every progress.Interval bytes.
+func encodeToHuffmanCodes(ctx context.Context, uncompressed *vector.Vector[byte], codes *dictionary.Dictionary[byte, huffmanCode], writer *bitio.BitWriter, report progress.Func) error {
+	for i := 0; i < uncompressed.Size(); i++ {
+		if i%progress.Interval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
 			}
+
+			report.Report(i, int(writer.BitsWritten()/8))
 		}
 
-		compressedCodes.Append(encodedCode)
-		currentCode = vector.New(0, 8)
-		encodedCode = byte(0)
+		code, _ := codes.Get(uncompressed.MustGet(i))
+
+		if err := writer.WriteBits(code.bits, code.length); err != nil {
+			return err
+		}
 	}
 
-	lastByteInBits = totalBits % 8
+	return nil
+}
 
-	if lastByteInBits == 0 {
-		lastByteInBits = 8
+// readError converts the end of the huffman codes in the middle of a code into
+// ErrTruncated.
+func readError(err error) error {
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: the last huffman code is incomplete", ErrTruncated)
 	}
 
-	return compressedCodes, lastByteInBits
+	return fmt.Errorf("could not read huffman code: %w", err)
 }
 
 func isLeafNode(n *huffmanTreeNode) bool {
@@ -2,11 +2,18 @@
 package lzw
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 
+	"github.com/mjjs/gompressor/bitio"
 	"github.com/mjjs/gompressor/datastructure/dictionary"
 	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/limit"
+	"github.com/mjjs/gompressor/progress"
 )
 
 // DictionarySize determines how large the dictionary used in compression can
@@ -23,40 +30,121 @@ const (
 	XL                = 65535
 )
 
+// dictionarySizeNames maps the names of the dictionary sizes into the sizes.
+var dictionarySizeNames = map[string]DictionarySize{
+	"xs": XS,
+	"s":  S,
+	"m":  M,
+	"l":  L,
+	"xl": XL,
+}
+
 const initialDictSize uint16 = 255
 
+// codeBits is the number of bits each code is stored in by WriteCodes.
+const codeBits uint = 16
+
+// MaxExpansion is the maximum number of decompressed bytes per byte of codes
+// written by WriteCodes. A code can not stand for a phrase longer than the
+// largest dictionary.
+const MaxExpansion int = (int(XL) + 1) / int(codeBits/8)
+
+// ErrTruncated is returned when compressed data ends in the middle of a code.
+var ErrTruncated = errors.New("truncated data")
+
+// ErrCorrupt is returned when compressed data is malformed.
+var ErrCorrupt = errors.New("corrupt data")
+
 // ErrBadCompressedCode represents an error that occurs when the LZW decompression
 // algorithm finds a code that is not valid for the assumed compression algorithm.
-var ErrBadCompressedCode = errors.New("bad compression code")
+// It wraps ErrCorrupt.
+var ErrBadCompressedCode = fmt.Errorf("%w: bad compression code", ErrCorrupt)
 
 // ErrInvalidDictionarySize represents an error indicating usage of an invalid
-// dictionary size. This can happen when attempting to compress or decompress
-// data.
+// dictionary size when compressing data. Compressed data storing an invalid
+// dictionary size is reported with ErrCorrupt.
 var ErrInvalidDictionarySize = errors.New("invalid dictionary size")
 
+// Observer is notified of the events of compression, for instrumenting how
+// the dictionary is used. The phrases are only valid during the calls.
+type Observer interface {
+	// Code is called for each code written, with the phrase it stands for.
+	Code(code uint16, phrase []byte)
+	// Add is called when a phrase is added into the dictionary.
+	Add(code uint16, phrase []byte)
+	// Reset is called when the full dictionary is reset, with the offset of
+	// the input at which the new dictionary starts.
+	Reset(offset int)
+}
+
+// ParseDictionarySize accepts the name, such as xs or XL, or the numeric value
+// of a dictionary size.
+func ParseDictionarySize(value string) (DictionarySize, error) {
+	if size, ok := dictionarySizeNames[strings.ToLower(value)]; ok {
+		return size, nil
+	}
+
+	n, err := strconv.ParseUint(value, 10, 16)
+	if err == nil && isValidDictionarySize(DictionarySize(n)) {
+		return DictionarySize(n), nil
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrInvalidDictionarySize, value)
+}
+
 // CompressWithDictSize takes a slice of uncompressed bytes and a dictionary size
 // as input and returns a slice of LZW codes that represent the compressed data.
 // This is mostly a utility function for testing how the dictionary size changes
 // the compression level.
-func CompressWithDictSize(uncompressed *vector.Vector, size DictionarySize) (*vector.Vector, error) {
+func CompressWithDictSize(uncompressed *vector.Vector[byte], size DictionarySize) (*vector.Vector[uint16], error) {
+	return CompressContext(context.Background(), uncompressed, size, nil)
+}
+
+// CompressContext compresses like CompressWithDictSize, but stops with the
+// error of the context when it is done. The progress is reported to report,
+// which may be nil, with the size of the codes written by WriteCodes as the
+// output size.
+func CompressContext(ctx context.Context, uncompressed *vector.Vector[byte], size DictionarySize, report progress.Func) (*vector.Vector[uint16], error) {
+	return compress(ctx, uncompressed, size, report, nil)
+}
+
+// CompressObserved compresses like CompressWithDictSize, notifying observer of
+// each code written, phrase added and dictionary reset.
+func CompressObserved(uncompressed *vector.Vector[byte], size DictionarySize, observer Observer) (*vector.Vector[uint16], error) {
+	return compress(context.Background(), uncompressed, size, nil, observer)
+}
+
+func compress(ctx context.Context, uncompressed *vector.Vector[byte], size DictionarySize, report progress.Func, observer Observer) (*vector.Vector[uint16], error) {
 	if !isValidDictionarySize(size) {
 		return nil, fmt.Errorf("%w: %d", ErrInvalidDictionarySize, int(size))
 	}
 
 	if uncompressed.Size() == 0 {
-		return uncompressed, nil
+		return vector.New[uint16](), nil
 	}
 
 	dict := createInitialCompressDictionary()
 
-	compressed := vector.New(0, uint(uncompressed.Size()))
+	compressed := vector.New[uint16](0, uint(uncompressed.Size()))
 	compressed.Append(uint16(size))
 
-	word := vector.New()
+	word := vector.New[byte]()
 
 	for i := 0; i < uncompressed.Size(); i++ {
+		if i%progress.Interval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			report.Report(i, compressed.Size()*int(codeBits/8))
+		}
+
 		if dict.Size() == int(size) {
 			dict = createInitialCompressDictionary()
+
+			if observer != nil {
+				observer.Reset(i)
+			}
 		}
 
 		byt := uncompressed.MustGet(i)
@@ -66,66 +154,105 @@ func CompressWithDictSize(uncompressed *vector.Vector, size DictionarySize) (*ve
 			word = newWord
 		} else {
 			code, _ := dict.Get(word.String())
-			compressed.Append(code.(uint16))
+			compressed.Append(code)
+
+			newCode := uint16(dict.Size())
+			dict.Set(newWord.String(), newCode)
+
+			if observer != nil {
+				observer.Code(code, word.Slice())
+				observer.Add(newCode, newWord.Slice())
+			}
 
-			dict.Set(newWord.String(), uint16(dict.Size()))
-			word = vector.New().AppendToCopy(byt)
+			word = vector.New[byte]().AppendToCopy(byt)
 		}
 	}
 
 	if word.Size() > 0 {
 		code, _ := dict.Get(word.String())
-		compressed.Append(code.(uint16))
+		compressed.Append(code)
+
+		if observer != nil {
+			observer.Code(code, word.Slice())
+		}
 	}
 
+	report.Report(uncompressed.Size(), compressed.Size()*int(codeBits/8))
+
 	return compressed, nil
 }
 
 // Compress is a shortcut for compressing with the largest dictionary size.
-func Compress(uncompressed *vector.Vector) (*vector.Vector, error) {
+func Compress(uncompressed *vector.Vector[byte]) (*vector.Vector[uint16], error) {
 	return CompressWithDictSize(uncompressed, XL)
 }
 
 // Decompress takes in a slice of LZW codes representing some compressed data
 // and outputs the decompressed data as a slice of bytes.
-// An error is returned if the decompression algorithm finds a bad LZW code.
-func Decompress(compressed *vector.Vector) (*vector.Vector, error) {
+// An error wrapping ErrCorrupt is returned if the data is compressed with an
+// invalid dictionary size or the decompression algorithm finds a bad LZW code.
+func Decompress(compressed *vector.Vector[uint16]) (*vector.Vector[byte], error) {
+	return DecompressWithLimits(compressed, limit.Limits{})
+}
+
+// DecompressWithLimits decompresses like Decompress, but stops with an error
+// wrapping limit.ErrExceeded as soon as the decompressed data exceeds the
+// limits. The ratio is calculated against the size of the codes written by
+// WriteCodes.
+func DecompressWithLimits(compressed *vector.Vector[uint16], limits limit.Limits) (*vector.Vector[byte], error) {
+	return DecompressContext(context.Background(), compressed, limits)
+}
+
+// DecompressContext decompresses like DecompressWithLimits, but stops with the
+// error of the context when it is done. The context is checked once every
+// progress.Interval codes.
+func DecompressContext(ctx context.Context, compressed *vector.Vector[uint16], limits limit.Limits) (*vector.Vector[byte], error) {
 	if compressed.Size() == 0 {
-		return compressed, nil
+		return vector.New[byte](), nil
 	}
 
-	size := compressed.MustGet(0).(uint16)
+	size := compressed.MustGet(0)
 	if !isValidDictionarySize(DictionarySize(size)) {
-		return nil, fmt.Errorf("the data is compressed with an invalid dictionary size %d", size)
+		return nil, fmt.Errorf("%w: the data is compressed with an invalid dictionary size %d", ErrCorrupt, size)
 	}
 
 	dict := createInitialDecompressDictionary()
+	compressedSize := compressed.Size() * int(codeBits/8)
 
-	result := vector.New()
-	word := vector.New()
+	result := vector.New[byte]()
+	word := vector.New[byte]()
 
 	for i := 1; i < compressed.Size(); i++ {
+		if (i-1)%progress.Interval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
 		if dict.Size() == int(size) {
 			dict = createInitialDecompressDictionary()
 		}
 
 		code := compressed.MustGet(i)
 
-		entry := vector.New()
+		entry := vector.New[byte]()
 
-		if c, ok := dict.Get(code); ok {
-			byteVector := c.(*vector.Vector)
+		if byteVector, ok := dict.Get(code); ok {
 
-			entry = vector.New(uint(byteVector.Size()))
+			entry = vector.New[byte](uint(byteVector.Size()))
 			for i := 0; i < byteVector.Size(); i++ {
 				entry.MustSet(i, byteVector.MustGet(i))
 			}
-		} else if int(code.(uint16)) == dict.Size() && word.Size() > 0 {
+		} else if int(code) == dict.Size() && word.Size() > 0 {
 			entry = word.AppendToCopy(word.MustGet(0))
 		} else {
 			return nil, fmt.Errorf("%w: %d", ErrBadCompressedCode, code)
 		}
 
+		if err := limits.Check(compressedSize, result.Size()+entry.Size()); err != nil {
+			return nil, err
+		}
+
 		for i := 0; i < entry.Size(); i++ {
 			result.Append(entry.MustGet(i))
 		}
@@ -141,8 +268,72 @@ func Decompress(compressed *vector.Vector) (*vector.Vector, error) {
 	return result, nil
 }
 
-func createInitialCompressDictionary() *dictionary.Dictionary {
-	dict := dictionary.NewWithSize(uint(initialDictSize))
+// WriteCodes writes the LZW codes into w. Each code is written as a 16-bit
+// big-endian integer.
+func WriteCodes(w io.Writer, codes *vector.Vector[uint16]) error {
+	writer := bitio.NewBitWriter(w, bitio.MSBFirst)
+
+	for i := 0; i < codes.Size(); i++ {
+		err := writer.WriteBits(uint64(codes.MustGet(i)), codeBits)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+// ReadCodes reads LZW codes written by WriteCodes from r until r is
+// exhausted. An error wrapping ErrTruncated is returned if r ends in the
+// middle of a code.
+func ReadCodes(r io.Reader) (*vector.Vector[uint16], error) {
+	codes := vector.New[uint16]()
+	reader := bitio.NewBitReader(r, bitio.MSBFirst)
+
+	for {
+		code, err := reader.ReadBits(codeBits)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, readError(err)
+		}
+
+		codes.Append(uint16(code))
+	}
+
+	return codes, nil
+}
+
+// ReadDictionarySize reads the dictionary size stored as the first code of the
+// codes written by WriteCodes, without reading the rest of the codes.
+func ReadDictionarySize(r io.Reader) (DictionarySize, error) {
+	reader := bitio.NewBitReader(r, bitio.MSBFirst)
+
+	code, err := reader.ReadBits(codeBits)
+	if err != nil {
+		return 0, readError(err)
+	}
+
+	size := DictionarySize(code)
+	if !isValidDictionarySize(size) {
+		return 0, fmt.Errorf("%w: invalid dictionary size %d", ErrCorrupt, int(size))
+	}
+
+	return size, nil
+}
+
+// readError converts the end of r in the middle of a code into ErrTruncated.
+func readError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: the input ends in the middle of a code", ErrTruncated)
+	}
+
+	return err
+}
+
+func createInitialCompressDictionary() *dictionary.Dictionary[string, uint16] {
+	dict := dictionary.NewWithSize[string, uint16](dictionary.StringHasher{}, uint(initialDictSize), dictionary.OpenAddressing())
 
 	for i := uint16(0); i <= initialDictSize; i++ {
 		dict.Set(string([]byte{byte(i)}), i)
@@ -151,11 +342,11 @@ func createInitialCompressDictionary() *dictionary.Dictionary {
 	return dict
 }
 
-func createInitialDecompressDictionary() *dictionary.Dictionary {
-	dict := dictionary.NewWithSize(uint(initialDictSize))
+func createInitialDecompressDictionary() *dictionary.Dictionary[uint16, *vector.Vector[byte]] {
+	dict := dictionary.NewWithSize[uint16, *vector.Vector[byte]](dictionary.IntegerHasher[uint16]{}, uint(initialDictSize), dictionary.OpenAddressing())
 
 	for i := uint16(0); i <= initialDictSize; i++ {
-		bv := vector.New(1)
+		bv := vector.New[byte](1)
 		bv.MustSet(0, byte(i))
 		dict.Set(i, bv)
 	}
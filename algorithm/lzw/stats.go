@@ -0,0 +1,165 @@
+package lzw
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// Phrase is a phrase written as a single code.
+type Phrase struct {
+	Code   uint16 `json:"code"`
+	Length int    `json:"length"`
+	Bytes  []byte `json:"bytes"`
+}
+
+// Text returns the phrase as text, with the bytes which are not printable
+// escaped as in a quoted Go string.
+func (p Phrase) Text() string {
+	quoted := strconv.Quote(string(p.Bytes))
+	return quoted[1 : len(quoted)-1]
+}
+
+// MarshalJSON encodes the phrase with its text along with the bytes, as the
+// bytes are encoded in base64.
+func (p Phrase) MarshalJSON() ([]byte, error) {
+	type phrase Phrase
+
+	return json.Marshal(struct {
+		phrase
+		Text string `json:"text"`
+	}{phrase(p), p.Text()})
+}
+
+// Generation describes the use of the dictionary between two resets.
+type Generation struct {
+	// Offset is the offset of the input at which the dictionary starts.
+	Offset int `json:"offset"`
+	// Entries is the number of phrases in the dictionary, including the
+	// initial phrases of single bytes.
+	Entries int `json:"entries"`
+	// Codes is the number of codes written.
+	Codes int `json:"codes"`
+	// UsedCodes is the number of distinct codes written.
+	UsedCodes int `json:"usedCodes"`
+
+	used map[uint16]bool
+}
+
+// Utilisation returns the percentage of the phrases in the dictionary which
+// were written at least once.
+func (g Generation) Utilisation() float64 {
+	if g.Entries == 0 {
+		return 0
+	}
+
+	return float64(g.UsedCodes) / float64(g.Entries) * 100
+}
+
+// Statistics is an Observer recording how the dictionary is used during
+// compression.
+type Statistics struct {
+	DictionarySize DictionarySize `json:"dictionarySize"`
+	InputSize      int            `json:"inputSize"`
+	// Codes is the number of codes written, excluding the dictionary size
+	// stored as the first code.
+	Codes int `json:"codes"`
+	// Resets lists the offsets of the input at which the dictionary was
+	// reset.
+	Resets      []int        `json:"resets"`
+	Generations []Generation `json:"generations"`
+	// PhraseLengths maps the length of each phrase written into the number of
+	// codes written for phrases of the length.
+	PhraseLengths map[int]int `json:"phraseLengths"`
+	// LongestPhrases are the longest distinct phrases written, longest first.
+	LongestPhrases []Phrase `json:"longestPhrases"`
+
+	longest int
+}
+
+// NewStatistics creates statistics of compressing with the dictionary size,
+// keeping the given number of longest phrases.
+func NewStatistics(size DictionarySize, longest int) *Statistics {
+	s := &Statistics{
+		DictionarySize: size,
+		Resets:         []int{},
+		PhraseLengths:  map[int]int{},
+		LongestPhrases: []Phrase{},
+		longest:        longest,
+	}
+
+	s.startGeneration(0)
+
+	return s
+}
+
+func (s *Statistics) startGeneration(offset int) {
+	s.Generations = append(s.Generations, Generation{
+		Offset:  offset,
+		Entries: int(initialDictSize) + 1,
+		used:    map[uint16]bool{},
+	})
+}
+
+func (s *Statistics) generation() *Generation {
+	return &s.Generations[len(s.Generations)-1]
+}
+
+// Code records a code written for the phrase.
+func (s *Statistics) Code(code uint16, phrase []byte) {
+	s.Codes++
+	s.InputSize += len(phrase)
+	s.PhraseLengths[len(phrase)]++
+
+	g := s.generation()
+	g.Codes++
+
+	if !g.used[code] {
+		g.used[code] = true
+		g.UsedCodes++
+	}
+
+	s.recordPhrase(code, phrase)
+}
+
+// Add records a phrase added into the dictionary.
+func (s *Statistics) Add(code uint16, phrase []byte) {
+	s.generation().Entries++
+}
+
+// Reset records a reset of the dictionary.
+func (s *Statistics) Reset(offset int) {
+	s.Resets = append(s.Resets, offset)
+	s.startGeneration(offset)
+}
+
+// recordPhrase keeps the phrase if it is one of the longest phrases.
+func (s *Statistics) recordPhrase(code uint16, phrase []byte) {
+	if s.longest <= 0 {
+		return
+	}
+
+	n := len(s.LongestPhrases)
+	if n == s.longest && len(phrase) <= s.LongestPhrases[n-1].Length {
+		return
+	}
+
+	for _, p := range s.LongestPhrases {
+		if bytes.Equal(p.Bytes, phrase) {
+			return
+		}
+	}
+
+	i := sort.Search(n, func(i int) bool {
+		return s.LongestPhrases[i].Length < len(phrase)
+	})
+
+	s.LongestPhrases = append(s.LongestPhrases, Phrase{})
+	copy(s.LongestPhrases[i+1:], s.LongestPhrases[i:])
+	s.LongestPhrases[i] = Phrase{Code: code, Length: len(phrase), Bytes: append([]byte{}, phrase...)}
+
+	if len(s.LongestPhrases) > s.longest {
+		s.LongestPhrases = s.LongestPhrases[:s.longest]
+	}
+}
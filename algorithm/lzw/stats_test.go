@@ -0,0 +1,177 @@
+package lzw
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/mjjs/gompressor/datastructure/vector"
+)
+
+func TestStatisticsCountCodesAndPhrases(t *testing.T) {
+	input := "TOBEORNOTTOBEORTOBEORNOT#"
+	stats := NewStatistics(XS, 3)
+
+	compressed, err := CompressObserved(vector.FromSlice([]byte(input)), XS, stats)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if stats.Codes != compressed.Size()-1 {
+		t.Errorf("Expected %d codes, got %d", compressed.Size()-1, stats.Codes)
+	}
+
+	if stats.InputSize != len(input) {
+		t.Errorf("Expected input size %d, got %d", len(input), stats.InputSize)
+	}
+
+	histogramCodes := 0
+	histogramBytes := 0
+
+	for length, count := range stats.PhraseLengths {
+		histogramCodes += count
+		histogramBytes += length * count
+	}
+
+	if histogramCodes != stats.Codes || histogramBytes != len(input) {
+		t.Errorf("Expected the histogram to sum to %d codes and %d bytes, got %d and %d",
+			stats.Codes, len(input), histogramCodes, histogramBytes)
+	}
+
+	if len(stats.Resets) != 0 || len(stats.Generations) != 1 {
+		t.Errorf("Expected no resets, got %v", stats.Resets)
+	}
+
+	// Each code except the last one adds a phrase into the dictionary.
+	if expected := 256 + stats.Codes - 1; stats.Generations[0].Entries != expected {
+		t.Errorf("Expected %d entries, got %d", expected, stats.Generations[0].Entries)
+	}
+}
+
+func TestStatisticsRecordResets(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	input := make([]byte, 20000)
+	random.Read(input)
+
+	stats := NewStatistics(XS, 0)
+
+	if _, err := CompressObserved(vector.FromSlice(input), XS, stats); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if len(stats.Resets) == 0 {
+		t.Fatal("Expected the dictionary to be reset")
+	}
+
+	if len(stats.Generations) != len(stats.Resets)+1 {
+		t.Errorf("Expected %d generations, got %d", len(stats.Resets)+1, len(stats.Generations))
+	}
+
+	for i, offset := range stats.Resets {
+		if stats.Generations[i+1].Offset != offset {
+			t.Errorf("Expected generation to start at %d, got %d", offset, stats.Generations[i+1].Offset)
+		}
+
+		if stats.Generations[i].Entries != int(XS) {
+			t.Errorf("Expected a full dictionary of %d entries, got %d", XS, stats.Generations[i].Entries)
+		}
+
+		if u := stats.Generations[i].Utilisation(); u <= 0 || u > 100 {
+			t.Errorf("Expected utilisation between 0 and 100, got %f", u)
+		}
+	}
+
+	if len(stats.LongestPhrases) != 0 {
+		t.Errorf("Expected no phrases to be kept, got %d", len(stats.LongestPhrases))
+	}
+}
+
+func TestStatisticsKeepLongestPhrases(t *testing.T) {
+	input := strings.Repeat("a", 100)
+	stats := NewStatistics(XL, 3)
+
+	if _, err := CompressObserved(vector.FromSlice([]byte(input)), XL, stats); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	// The phrases of a run of a single byte grow by one byte at a time:
+	// 1 + 2 + ... + 13 = 91, followed by the remaining 9 bytes.
+	expected := []int{13, 12, 11}
+
+	if len(stats.LongestPhrases) != len(expected) {
+		t.Fatalf("Expected %d phrases, got %d", len(expected), len(stats.LongestPhrases))
+	}
+
+	for i, phrase := range stats.LongestPhrases {
+		if phrase.Length != expected[i] || string(phrase.Bytes) != strings.Repeat("a", expected[i]) {
+			t.Errorf("Expected a phrase of length %d, got %+v", expected[i], phrase)
+		}
+	}
+}
+
+func TestStatisticsKeepBinaryPhrasesInJSON(t *testing.T) {
+	input := bytes.Repeat([]byte{0xff, 0xfe, 0x00}, 100)
+	stats := NewStatistics(XL, 1)
+
+	if _, err := CompressObserved(vector.FromSlice(input), XL, stats); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	encoded, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	var text struct {
+		LongestPhrases []struct {
+			Text string `json:"text"`
+		} `json:"longestPhrases"`
+	}
+
+	if err := json.Unmarshal(encoded, &text); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if len(text.LongestPhrases) != 1 || !strings.HasPrefix(text.LongestPhrases[0].Text, `\xff\xfe\x00`) {
+		t.Errorf("Expected the text to start with %s, got %+v", `\xff\xfe\x00`, text.LongestPhrases)
+	}
+
+	var decoded Statistics
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	expected := stats.LongestPhrases[0].Bytes
+	if len(decoded.LongestPhrases) != 1 || !bytes.Equal(decoded.LongestPhrases[0].Bytes, expected) {
+		t.Errorf("Expected %v, got %+v", expected, decoded.LongestPhrases)
+	}
+}
+
+func TestParseDictionarySize(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected DictionarySize
+		err      error
+	}{
+		{value: "xs", expected: XS},
+		{value: "XL", expected: XL},
+		{value: "4095", expected: M},
+		{value: "1000", err: ErrInvalidDictionarySize},
+		{value: "huge", err: ErrInvalidDictionarySize},
+	}
+
+	for _, testCase := range testCases {
+		size, err := ParseDictionarySize(testCase.value)
+
+		if !errors.Is(err, testCase.err) {
+			t.Errorf("%s: Expected %v, got %v", testCase.value, testCase.err, err)
+		}
+
+		if size != testCase.expected {
+			t.Errorf("%s: Expected %d, got %d", testCase.value, testCase.expected, size)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mjjs/gompressor/archive"
+	"github.com/mjjs/gompressor/codec"
+)
+
+// subcommandArguments are the arguments of the archive subcommands shown in
+// their usage.
+var subcommandArguments = map[string]string{
+	"archive create":  "-o archive|- [flags] path...",
+	"archive list":    "archive|-",
+	"archive extract": "[-dir directory] archive|-",
+}
+
+// runArchive runs the archive command with the subcommand and the arguments
+// following it.
+func runArchive(name string, args []string) int {
+	if len(args) == 0 {
+		newFlagSet(name).Usage()
+		return 2
+	}
+
+	flags := newFlagSet(name + " " + args[0])
+
+	switch args[0] {
+	case "create":
+		codecFlags := addCodecFlags(flags, "lzw")
+		outputFileFlag := flags.String("o", "", "archive file to create, - writes into the standard output")
+		forceFlag := flags.Bool("f", false, "overwrite an existing archive file")
+		flags.Parse(args[1:])
+
+		if *outputFileFlag == "" || flags.NArg() == 0 {
+			flags.Usage()
+			return 2
+		}
+
+		c, err := codecFlags.codec()
+		if err != nil {
+			return fail(err)
+		}
+
+		if err := checkOutput(stdio, *outputFileFlag, *forceFlag); err != nil {
+			return fail(err)
+		}
+
+		return createArchive(*outputFileFlag, flags.Args(), c)
+
+	case "list":
+		flags.Parse(args[1:])
+
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return 2
+		}
+
+		return listArchive(flags.Arg(0))
+
+	case "extract":
+		dirFlag := flags.String("dir", ".", "directory to extract the archive into")
+		flags.Parse(args[1:])
+
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return 2
+		}
+
+		return extractArchive(flags.Arg(0), *dirFlag)
+
+	case "-h", "-help", "--help":
+		newFlagSet(name).Usage()
+		return 0
+
+	default:
+		newFlagSet(name).Usage()
+		return 2
+	}
+}
+
+func createArchive(filename string, paths []string, c codec.Codec) int {
+	if filename == stdio && isTerminal(os.Stdout) {
+		return fail(fmt.Errorf("archive not written to a terminal, redirect the output"))
+	}
+
+	file, err := createOutput(filename)
+	if err != nil {
+		return fail(fmt.Errorf("could not create archive: %w", err))
+	}
+
+	defer file.Close()
+
+	err = archive.Create(file, paths, c)
+	if err == nil {
+		err = file.Commit()
+	}
+
+	if err != nil {
+		return fail(fmt.Errorf("could not create archive: %w", err))
+	}
+
+	fmt.Fprintf(os.Stderr, "Created archive %s\n", displayName(filename, false))
+
+	return 0
+}
+
+func listArchive(filename string) int {
+	file, err := openInput(filename)
+	if err != nil {
+		return fail(fmt.Errorf("could not open archive: %w", err))
+	}
+
+	defer file.Close()
+
+	headers, err := archive.List(file)
+	if err != nil {
+		return fail(fmt.Errorf("could not list archive: %w", err))
+	}
+
+	for _, header := range headers {
+		name := header.Name
+
+		switch header.Type {
+		case archive.TypeDir:
+			name += "/"
+		case archive.TypeSymlink:
+			name += " -> " + header.Linkname
+		}
+
+		fmt.Printf("%s %10d %10d %s %s\n",
+			entryMode(header), header.Size, header.CompressedSize,
+			header.ModTime.Format("2006-01-02 15:04"), name)
+	}
+
+	return 0
+}
+
+func extractArchive(filename string, dir string) int {
+	file, err := openInput(filename)
+	if err != nil {
+		return fail(fmt.Errorf("could not open archive: %w", err))
+	}
+
+	defer file.Close()
+
+	err = archive.Extract(file, dir)
+	if err != nil {
+		return fail(fmt.Errorf("could not extract archive: %w", err))
+	}
+
+	fmt.Fprintf(os.Stderr, "Extracted %s into %s\n", displayName(filename, true), dir)
+
+	return 0
+}
+
+func entryMode(header *archive.Header) os.FileMode {
+	switch header.Type {
+	case archive.TypeDir:
+		return header.Mode | os.ModeDir
+	case archive.TypeSymlink:
+		return header.Mode | os.ModeSymlink
+	default:
+		return header.Mode
+	}
+}
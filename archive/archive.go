@@ -0,0 +1,328 @@
+// Package archive implements an archive format which stores multiple files,
+// directories and symbolic links along with their metadata. The contents of
+// each file are compressed separately.
+//
+// An archive starts with the bytes "GMPA" and a version byte, followed by the
+// entries. Each entry consists of a header and the compressed contents:
+//
+//	type             1 byte
+//	algorithm        1 byte
+//	mode             uint32
+//	modification     int64, nanoseconds since the Unix epoch
+//	name             uint16 length followed by the name
+//	link target      uint16 length followed by the target
+//	size             uint64, the size of the uncompressed contents
+//	compressed size  uint64, the size of the compressed contents
+//	contents         compressed size bytes
+//
+// All integers are stored in big-endian byte order. The archive ends after
+// the last entry. As the header contains the compressed size, the entries can
+// be listed without decompressing the contents.
+package archive
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"time"
+
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/limit"
+)
+
+// EntryType is the type of an archive entry.
+type EntryType byte
+
+const (
+	// TypeFile is a regular file.
+	TypeFile EntryType = iota
+	// TypeDir is a directory.
+	TypeDir
+	// TypeSymlink is a symbolic link.
+	TypeSymlink
+)
+
+const version byte = 1
+
+var magic = []byte("GMPA")
+
+// ErrInvalidHeader is returned when the archive or an entry header is not valid.
+var ErrInvalidHeader = errors.New("invalid archive header")
+
+// ErrSizeMismatch is returned when the contents of an entry do not match the
+// size in its header.
+var ErrSizeMismatch = errors.New("entry size mismatch")
+
+// Header describes an archive entry.
+type Header struct {
+	Name           string
+	Type           EntryType
+	Mode           fs.FileMode
+	ModTime        time.Time
+	Linkname       string
+	Size           int64
+	CompressedSize int64
+	Algorithm      codec.Algorithm
+}
+
+// Writer writes entries into an archive.
+type Writer struct {
+	w     *bufio.Writer
+	codec codec.Codec
+}
+
+// NewWriter writes the archive header into w and returns a Writer for adding
+// the entries, which compresses the contents of the files with the codec.
+// Close must be called after the last entry.
+func NewWriter(w io.Writer, c codec.Codec) (*Writer, error) {
+	writer := &Writer{w: bufio.NewWriter(w), codec: c}
+
+	if _, err := writer.w.Write(magic); err != nil {
+		return nil, err
+	}
+
+	if err := writer.w.WriteByte(version); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+// WriteEntry compresses the contents with the codec of the writer and writes
+// the entry into the archive. The algorithm and the sizes of the header are
+// filled in by WriteEntry. Directories and symbolic links have no contents, so contents
+// should be nil for them.
+func (w *Writer) WriteEntry(header Header, contents *vector.Vector[byte]) error {
+	if len(header.Name) > math.MaxUint16 || len(header.Linkname) > math.MaxUint16 {
+		return fmt.Errorf("%w: name of %s is too long", ErrInvalidHeader, header.Name)
+	}
+
+	if contents == nil || header.Type != TypeFile {
+		contents = vector.New[byte]()
+	}
+
+	compressed, err := w.codec.Compress(contents)
+	if err != nil {
+		return err
+	}
+
+	fields := []interface{}{
+		header.Type,
+		w.codec.Algorithm(),
+		uint32(header.Mode),
+		header.ModTime.UnixNano(),
+		uint16(len(header.Name)),
+		[]byte(header.Name),
+		uint16(len(header.Linkname)),
+		[]byte(header.Linkname),
+		uint64(contents.Size()),
+		uint64(compressed.Size()),
+		compressed.Slice(),
+	}
+
+	for _, field := range fields {
+		if err := binary.Write(w.w, binary.BigEndian, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close flushes the archive into the underlying writer.
+func (w *Writer) Close() error {
+	return w.w.Flush()
+}
+
+// Reader reads the entries of an archive.
+type Reader struct {
+	r       io.Reader
+	current *Header
+	unread  int64
+}
+
+// NewReader reads the archive header from r and returns a Reader for reading
+// the entries. If r implements io.Seeker, the contents which are not read
+// are skipped by seeking past them.
+func NewReader(r io.Reader) (*Reader, error) {
+	header := make([]byte, len(magic)+1)
+
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidHeader, err)
+	}
+
+	if string(header[:len(magic)]) != string(magic) {
+		return nil, fmt.Errorf("%w: not an archive", ErrInvalidHeader)
+	}
+
+	if header[len(magic)] != version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidHeader, header[len(magic)])
+	}
+
+	return &Reader{r: r}, nil
+}
+
+// Next skips the rest of the current entry and returns the header of the next
+// entry. io.EOF is returned when there are no more entries.
+func (r *Reader) Next() (*Header, error) {
+	if err := r.skip(); err != nil {
+		return nil, err
+	}
+
+	var entryType [1]byte
+	if _, err := io.ReadFull(r.r, entryType[:]); err != nil {
+		return nil, err
+	}
+
+	if EntryType(entryType[0]) > TypeSymlink {
+		return nil, fmt.Errorf("%w: unknown entry type %d", ErrInvalidHeader, entryType[0])
+	}
+
+	var fixed struct {
+		Algorithm codec.Algorithm
+		Mode      uint32
+		ModTime   int64
+	}
+
+	if err := binary.Read(r.r, binary.BigEndian, &fixed); err != nil {
+		return nil, truncated(err)
+	}
+
+	name, err := r.readString()
+	if err != nil {
+		return nil, err
+	}
+
+	linkname, err := r.readString()
+	if err != nil {
+		return nil, err
+	}
+
+	var sizes struct {
+		Size           uint64
+		CompressedSize uint64
+	}
+
+	if err := binary.Read(r.r, binary.BigEndian, &sizes); err != nil {
+		return nil, truncated(err)
+	}
+
+	if sizes.Size > math.MaxInt32 || sizes.CompressedSize > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: entry %s is too large", ErrInvalidHeader, name)
+	}
+
+	header := &Header{
+		Name:           name,
+		Type:           EntryType(entryType[0]),
+		Mode:           fs.FileMode(fixed.Mode),
+		ModTime:        time.Unix(0, fixed.ModTime),
+		Linkname:       linkname,
+		Size:           int64(sizes.Size),
+		CompressedSize: int64(sizes.CompressedSize),
+		Algorithm:      fixed.Algorithm,
+	}
+
+	r.current = header
+	r.unread = header.CompressedSize
+
+	return header, nil
+}
+
+// ReadContents reads and decompresses the contents of the current entry. The
+// contents are not allowed to decompress into more bytes than the size in the
+// header.
+func (r *Reader) ReadContents() (*vector.Vector[byte], error) {
+	if r.current == nil {
+		return nil, errors.New("no current entry")
+	}
+
+	// The compressed contents are read in pieces instead of allocating the
+	// size in the header up front, as a truncated archive may not have them.
+	compressed, err := io.ReadAll(io.LimitReader(r.r, r.unread))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(compressed)) != r.unread {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	r.unread = 0
+
+	// Empty contents are stored without any compressed data, and a limit
+	// of zero bytes would not limit anything.
+	if r.current.Size == 0 {
+		if len(compressed) > 0 {
+			return nil, fmt.Errorf("%w: %s has %d compressed bytes, expected none",
+				ErrSizeMismatch, r.current.Name, len(compressed))
+		}
+
+		return vector.New[byte](), nil
+	}
+
+	c, err := codec.ForAlgorithm(r.current.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", r.current.Name, err)
+	}
+
+	contents, err := codec.DecompressWithLimits(c, vector.FromSlice(compressed), limit.Limits{MaxOutput: int(r.current.Size)})
+	if errors.Is(err, limit.ErrExceeded) {
+		return nil, fmt.Errorf("%w: %s has more than %d bytes", ErrSizeMismatch, r.current.Name, r.current.Size)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", r.current.Name, err)
+	}
+
+	if int64(contents.Size()) != r.current.Size {
+		return nil, fmt.Errorf("%w: %s has %d bytes, expected %d",
+			ErrSizeMismatch, r.current.Name, contents.Size(), r.current.Size)
+	}
+
+	return contents, nil
+}
+
+func (r *Reader) skip() error {
+	if r.unread == 0 {
+		return nil
+	}
+
+	if seeker, ok := r.r.(io.Seeker); ok {
+		_, err := seeker.Seek(r.unread, io.SeekCurrent)
+		r.unread = 0
+		return err
+	}
+
+	_, err := io.CopyN(io.Discard, r.r, r.unread)
+	r.unread = 0
+
+	return truncated(err)
+}
+
+func (r *Reader) readString() (string, error) {
+	var length uint16
+	if err := binary.Read(r.r, binary.BigEndian, &length); err != nil {
+		return "", truncated(err)
+	}
+
+	s := make([]byte, length)
+	if _, err := io.ReadFull(r.r, s); err != nil {
+		return "", truncated(err)
+	}
+
+	return string(s), nil
+}
+
+// truncated converts io.EOF in the middle of an entry into io.ErrUnexpectedEOF.
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
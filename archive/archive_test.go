@@ -0,0 +1,294 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/datastructure/vector"
+)
+
+var lzwCodec, _ = codec.ForAlgorithm(codec.LZW)
+
+func bytesToVector(b []byte) *vector.Vector[byte] {
+	v := vector.New[byte]()
+	v.Append(b...)
+
+	return v
+}
+
+func writeArchive(t *testing.T, entries map[string][]byte, order []string) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+
+	writer, err := NewWriter(buf, lzwCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range order {
+		header := Header{
+			Name:    name,
+			Type:    TypeFile,
+			Mode:    0644,
+			ModTime: time.Unix(1600000000, 0),
+		}
+
+		if err := writer.WriteEntry(header, bytesToVector(entries[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf
+}
+
+func TestReaderReturnsWrittenEntries(t *testing.T) {
+	entries := map[string][]byte{
+		"a.txt":     []byte("TOBEORNOTTOBEORTOBEORNOT"),
+		"dir/b.txt": []byte("hello hello hello"),
+		"empty":     {},
+	}
+	order := []string{"a.txt", "dir/b.txt", "empty"}
+
+	reader, err := NewReader(writeArchive(t, entries, order))
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	for _, name := range order {
+		header, err := reader.Next()
+		if err != nil {
+			t.Fatalf("Expected nil error, got %s", err)
+		}
+
+		if header.Name != name || header.Size != int64(len(entries[name])) || header.Mode != 0644 || header.Algorithm != codec.LZW {
+			t.Errorf("Unexpected header %+v", header)
+		}
+
+		if !header.ModTime.Equal(time.Unix(1600000000, 0)) {
+			t.Errorf("Expected %s, got %s", time.Unix(1600000000, 0), header.ModTime)
+		}
+
+		contents, err := reader.ReadContents()
+		if err != nil {
+			t.Errorf("Expected nil error, got %s", err)
+		} else if !bytes.Equal(entries[name], contents.Slice()) {
+			t.Errorf("Expected %q, got %q", entries[name], contents.Slice())
+		}
+	}
+
+	if _, err := reader.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected %s, got %v", io.EOF, err)
+	}
+}
+
+func TestListSkipsContents(t *testing.T) {
+	entries := map[string][]byte{"a": []byte("aaaa"), "b": []byte("bbbbbbbb")}
+	archive := writeArchive(t, entries, []string{"a", "b"})
+
+	headers, err := List(bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	names := []string{}
+	for _, header := range headers {
+		names = append(names, header.Name)
+	}
+
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+
+	// Listing from a reader which can not seek discards the contents instead.
+	if headers, err := List(io.MultiReader(archive)); err != nil || len(headers) != 2 {
+		t.Errorf("Expected %d headers and nil error, got %d and %v", 2, len(headers), err)
+	}
+}
+
+func TestNewReaderRejectsInvalidHeader(t *testing.T) {
+	for _, input := range []string{"", "GMPB\x01", "GMPA\x02"} {
+		if _, err := NewReader(bytes.NewReader([]byte(input))); !errors.Is(err, ErrInvalidHeader) {
+			t.Errorf("%q: Expected %s, got %v", input, ErrInvalidHeader, err)
+		}
+	}
+}
+
+func TestNextReturnsErrorOnTruncatedArchive(t *testing.T) {
+	archive := writeArchive(t, map[string][]byte{"name": []byte("contents")}, []string{"name"})
+	truncated := archive.Bytes()[:12]
+
+	reader, _ := NewReader(bytes.NewReader(truncated))
+	if _, err := reader.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected %s, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestReadContentsLimitsOutputToHeaderSize(t *testing.T) {
+	contents := bytes.Repeat([]byte("a"), 100000)
+	compressed, _ := lzwCodec.Compress(bytesToVector(contents))
+
+	testCases := []struct {
+		name     string
+		size     int
+		expected error
+	}{
+		{name: "expands beyond the size", size: 10, expected: ErrSizeMismatch},
+		{name: "empty", size: 0, expected: ErrSizeMismatch},
+		{name: "shrinks below the size", size: len(contents) + 1, expected: ErrSizeMismatch},
+		{name: "exact", size: len(contents)},
+	}
+
+	for _, testCase := range testCases {
+		data := writeArchive(t, map[string][]byte{"bomb": contents}, []string{"bomb"}).Bytes()
+
+		// The size is followed by the compressed size and the contents at the
+		// end of the archive.
+		sizeOffset := len(data) - compressed.Size() - 16
+		binary.BigEndian.PutUint64(data[sizeOffset:], uint64(testCase.size))
+
+		reader, _ := NewReader(bytes.NewReader(data))
+		if _, err := reader.Next(); err != nil {
+			t.Fatalf("%s: Expected nil error, got %s", testCase.name, err)
+		}
+
+		_, err := reader.ReadContents()
+		if !errors.Is(err, testCase.expected) {
+			t.Errorf("%s: Expected %v, got %v", testCase.name, testCase.expected, err)
+		}
+	}
+}
+
+func TestReadContentsReturnsErrorOnTruncatedContents(t *testing.T) {
+	archive := writeArchive(t, map[string][]byte{"name": []byte("contents")}, []string{"name"})
+	truncated := archive.Bytes()[:archive.Len()-1]
+
+	reader, _ := NewReader(bytes.NewReader(truncated))
+	if _, err := reader.Next(); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if _, err := reader.ReadContents(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected %s, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestValidName(t *testing.T) {
+	testCases := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "file", valid: true},
+		{name: "dir/file", valid: true},
+		{name: "./file", valid: true},
+		{name: "dir/..file", valid: true},
+		{name: "", valid: false},
+		{name: "/etc/passwd", valid: false},
+		{name: "../file", valid: false},
+		{name: "dir/../../file", valid: false},
+		{name: "dir/..", valid: false},
+		{name: "dir\\..\\file", valid: false},
+	}
+
+	for _, testCase := range testCases {
+		if actual := ValidName(testCase.name); actual != testCase.valid {
+			t.Errorf("%q: Expected %t, got %t", testCase.name, testCase.valid, actual)
+		}
+	}
+}
+
+func TestCreateAndExtractPreserveTree(t *testing.T) {
+	source := t.TempDir()
+	root := filepath.Join(source, "root")
+	modTime := time.Unix(1500000000, 0)
+
+	os.MkdirAll(filepath.Join(root, "sub"), 0755)
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("TOBEORNOTTOBEORTOBEORNOT"), 0600)
+	os.WriteFile(filepath.Join(root, "sub", "b.sh"), []byte("#!/bin/sh\necho hi\n"), 0755)
+	os.Chtimes(filepath.Join(root, "a.txt"), modTime, modTime)
+
+	if err := os.Symlink("a.txt", filepath.Join(root, "link")); err != nil {
+		t.Skipf("symbolic links not supported: %s", err)
+	}
+
+	os.Chmod(filepath.Join(root, "sub"), 0750)
+	os.Chtimes(filepath.Join(root, "sub"), modTime, modTime)
+
+	for _, algorithm := range []codec.Algorithm{codec.Huffman, codec.LZW} {
+		c, _ := codec.ForAlgorithm(algorithm)
+
+		buf := new(bytes.Buffer)
+		if err := Create(buf, []string{root}, c); err != nil {
+			t.Fatalf("%s: Expected nil error, got %s", algorithm, err)
+		}
+
+		destination := t.TempDir()
+		if err := Extract(bytes.NewReader(buf.Bytes()), destination); err != nil {
+			t.Fatalf("%s: Expected nil error, got %s", algorithm, err)
+		}
+
+		extracted := filepath.Join(destination, "root")
+
+		if contents, _ := os.ReadFile(filepath.Join(extracted, "sub", "b.sh")); string(contents) != "#!/bin/sh\necho hi\n" {
+			t.Errorf("%s: Unexpected contents %q", algorithm, contents)
+		}
+
+		info, err := os.Stat(filepath.Join(extracted, "a.txt"))
+		if err != nil {
+			t.Fatalf("%s: Expected nil error, got %s", algorithm, err)
+		}
+
+		if info.Mode().Perm() != 0600 || !info.ModTime().Equal(modTime) {
+			t.Errorf("%s: Expected mode %s and time %s, got %s and %s",
+				algorithm, fs.FileMode(0600), modTime, info.Mode().Perm(), info.ModTime())
+		}
+
+		if info, err := os.Stat(filepath.Join(extracted, "sub")); err != nil || info.Mode().Perm() != 0750 || !info.ModTime().Equal(modTime) {
+			t.Errorf("%s: Unexpected directory metadata %v %v", algorithm, info, err)
+		}
+
+		if target, err := os.Readlink(filepath.Join(extracted, "link")); err != nil || target != "a.txt" {
+			t.Errorf("%s: Expected link to %s, got %q and %v", algorithm, "a.txt", target, err)
+		}
+	}
+}
+
+func TestExtractRejectsPathTraversal(t *testing.T) {
+	for _, name := range []string{"../evil", "/tmp/evil", "a/../../evil"} {
+		archive := writeArchive(t, map[string][]byte{name: []byte("evil")}, []string{name})
+		destination := t.TempDir()
+
+		if err := Extract(archive, filepath.Join(destination, "out")); !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("%q: Expected %s, got %v", name, ErrUnsafePath, err)
+		}
+	}
+}
+
+func TestExtractRejectsWritingThroughSymlink(t *testing.T) {
+	outside := t.TempDir()
+	buf := new(bytes.Buffer)
+	writer, _ := NewWriter(buf, lzwCodec)
+
+	writer.WriteEntry(Header{Name: "link", Type: TypeSymlink, Linkname: outside}, nil)
+	writer.WriteEntry(Header{Name: "link/evil", Type: TypeFile, Mode: 0644}, bytesToVector([]byte("evil")))
+	writer.Close()
+
+	if err := Extract(buf, t.TempDir()); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("Expected %s, got %v", ErrUnsafePath, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outside, "evil")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected file outside of the destination not to be written, got %v", err)
+	}
+}
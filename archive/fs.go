@@ -0,0 +1,293 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/fileio"
+)
+
+// ErrUnsafePath is returned when extracting an entry whose name would place
+// it outside of the extraction directory.
+var ErrUnsafePath = errors.New("unsafe entry path")
+
+// Create writes an archive of the given paths into w, compressing the files
+// with the codec. Directories are added recursively. The entries are named
+// relative to the parent directory of each given path.
+func Create(w io.Writer, paths []string, c codec.Codec) error {
+	writer, err := NewWriter(w, c)
+	if err != nil {
+		return err
+	}
+
+	for _, root := range paths {
+		parent := filepath.Dir(filepath.Clean(root))
+
+		err := filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			name, err := filepath.Rel(parent, file)
+			if err != nil {
+				return err
+			}
+
+			return addEntry(writer, file, filepath.ToSlash(name))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func addEntry(writer *Writer, file string, name string) error {
+	info, err := os.Lstat(file)
+	if err != nil {
+		return err
+	}
+
+	header := Header{
+		Name:    name,
+		Mode:    info.Mode().Perm(),
+		ModTime: info.ModTime(),
+	}
+
+	switch {
+	case info.Mode().IsRegular():
+		header.Type = TypeFile
+
+		contents, err := fileio.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		return writer.WriteEntry(header, contents)
+
+	case info.IsDir():
+		header.Type = TypeDir
+
+	case info.Mode()&fs.ModeSymlink != 0:
+		header.Type = TypeSymlink
+
+		header.Linkname, err = os.Readlink(file)
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("%s: unsupported file type %s", file, info.Mode().Type())
+	}
+
+	return writer.WriteEntry(header, nil)
+}
+
+// List returns the headers of all the entries in the archive without
+// decompressing their contents.
+func List(r io.Reader) ([]*Header, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers []*Header
+
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return headers, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		headers = append(headers, header)
+	}
+}
+
+// Extract extracts the archive into dir. Entries whose names are absolute,
+// contain ".." or lead through a symbolic link are rejected with
+// ErrUnsafePath. Existing files are replaced.
+func Extract(r io.Reader, dir string) error {
+	reader, err := NewReader(r)
+	if err != nil {
+		return err
+	}
+
+	// The metadata of the directories is set after all the entries have been
+	// extracted, as extracting the entries modifies the directories.
+	var directories []*Header
+
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if !ValidName(header.Name) {
+			return fmt.Errorf("%w: %s", ErrUnsafePath, header.Name)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+
+		if err := checkSymlinks(dir, header.Name, header.Type == TypeDir); err != nil {
+			return err
+		}
+
+		switch header.Type {
+		case TypeFile:
+			err = extractFile(reader, header, target)
+		case TypeDir:
+			err = os.MkdirAll(target, 0755)
+			directories = append(directories, header)
+		case TypeSymlink:
+			err = extractSymlink(header, target)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := len(directories) - 1; i >= 0; i-- {
+		header := directories[i]
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+
+		if err := os.Chmod(target, header.Mode.Perm()); err != nil {
+			return err
+		}
+
+		if err := os.Chtimes(target, header.ModTime, header.ModTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidName reports whether an entry name is a relative slash-separated path
+// which stays inside of the directory it is extracted into.
+func ValidName(name string) bool {
+	if name == "" || path.IsAbs(name) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return false
+	}
+
+	if strings.ContainsAny(name, "\\\x00") {
+		return false
+	}
+
+	for _, element := range strings.Split(name, "/") {
+		if element == ".." {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkSymlinks returns ErrUnsafePath if any of the parent directories of the
+// entry inside of dir is a symbolic link, which could lead outside of dir. The
+// entry itself is checked as well when includeEntry is true.
+func checkSymlinks(dir string, name string, includeEntry bool) error {
+	elements := strings.Split(path.Clean(name), "/")
+	if !includeEntry {
+		elements = elements[:len(elements)-1]
+	}
+
+	current := dir
+
+	for _, element := range elements {
+		if element == "." {
+			continue
+		}
+
+		current = filepath.Join(current, element)
+
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s leads through a symbolic link", ErrUnsafePath, name)
+		}
+	}
+
+	return nil
+}
+
+func extractFile(reader *Reader, header *Header, target string) error {
+	contents, err := reader.ReadContents()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	if err := removeExisting(target); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, header.Mode.Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(contents.Slice())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chmod(target, header.Mode.Perm()); err != nil {
+		return err
+	}
+
+	return os.Chtimes(target, header.ModTime, header.ModTime)
+}
+
+func extractSymlink(header *Header, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	if err := removeExisting(target); err != nil {
+		return err
+	}
+
+	return os.Symlink(header.Linkname, target)
+}
+
+// removeExisting removes the file or symbolic link at target so that a new
+// file is never written through an existing symbolic link.
+func removeExisting(target string) error {
+	info, err := os.Lstat(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("%s: is a directory", target)
+	}
+
+	return os.Remove(target)
+}
@@ -0,0 +1,176 @@
+// Package batch implements processing of many files concurrently on a bounded
+// pool of workers.
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// ErrIsDirectory is returned for directories given without enabling recursion.
+var ErrIsDirectory = errors.New("is a directory")
+
+// ErrPanic is returned for files whose processing panicked.
+var ErrPanic = errors.New("panic while processing file")
+
+// Result is the outcome of processing a single file.
+type Result struct {
+	Input      string
+	Output     string
+	InputSize  int
+	OutputSize int
+	Err        error
+}
+
+// String returns a single line summary of the result. The output file is
+// left out of the summary when the result has none.
+func (r Result) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: %s", r.Input, r.Err)
+	}
+
+	if r.Output == "" {
+		return fmt.Sprintf("%s: %d -> %d bytes (%s)",
+			r.Input, r.InputSize, r.OutputSize, ratio(r.InputSize, r.OutputSize))
+	}
+
+	return fmt.Sprintf("%s -> %s: %d -> %d bytes (%s)",
+		r.Input, r.Output, r.InputSize, r.OutputSize, ratio(r.InputSize, r.OutputSize))
+}
+
+// Summary is the total of a batch of results.
+type Summary struct {
+	Files      int
+	Failed     int
+	InputSize  int
+	OutputSize int
+}
+
+// Summarize sums up the results. The sizes of failed files are not included
+// in the total sizes.
+func Summarize(results []Result) Summary {
+	summary := Summary{Files: len(results)}
+
+	for _, result := range results {
+		if result.Err != nil {
+			summary.Failed++
+			continue
+		}
+
+		summary.InputSize += result.InputSize
+		summary.OutputSize += result.OutputSize
+	}
+
+	return summary
+}
+
+// String returns a single line summary of the batch.
+func (s Summary) String() string {
+	return fmt.Sprintf("%d of %d files succeeded, %d -> %d bytes (%s)",
+		s.Files-s.Failed, s.Files, s.InputSize, s.OutputSize, ratio(s.InputSize, s.OutputSize))
+}
+
+// Files expands the paths into a list of regular files. Directories are
+// walked when recursive is true. Paths which can not be expanded are returned
+// as failed results instead of aborting the expansion of the other paths.
+func Files(paths []string, recursive bool) ([]string, []Result) {
+	var (
+		files    []string
+		failures []Result
+	)
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			failures = append(failures, Result{Input: path, Err: err})
+			continue
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		if !recursive {
+			failures = append(failures, Result{Input: path, Err: ErrIsDirectory})
+			continue
+		}
+
+		filepath.WalkDir(path, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				failures = append(failures, Result{Input: path, Err: err})
+				return nil
+			}
+
+			if entry.Type().IsRegular() {
+				files = append(files, path)
+			}
+
+			return nil
+		})
+	}
+
+	return files, failures
+}
+
+// Run calls work for each of the files using a pool of jobs goroutines, and
+// returns the results in the same order as the files. If jobs is less than
+// one, a goroutine is started for each CPU. A panic in work is recovered and
+// returned as the result of the file, so that the other files are processed.
+func Run(files []string, jobs int, work func(file string) Result) []Result {
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+
+	if jobs > len(files) {
+		jobs = len(files)
+	}
+
+	queue := make(chan int)
+	results := make([]Result, len(files))
+
+	var wg sync.WaitGroup
+	wg.Add(jobs)
+
+	for j := 0; j < jobs; j++ {
+		go func() {
+			defer wg.Done()
+
+			for i := range queue {
+				results[i] = runRecovered(files[i], work)
+			}
+		}()
+	}
+
+	for i := range files {
+		queue <- i
+	}
+
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+func runRecovered(file string, work func(file string) Result) (result Result) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = Result{Input: file, Err: fmt.Errorf("%w: %v", ErrPanic, r)}
+		}
+	}()
+
+	return work(file)
+}
+
+func ratio(inputSize int, outputSize int) string {
+	if inputSize == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.1f%%", float64(outputSize)/float64(inputSize)*100)
+}
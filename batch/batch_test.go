@@ -0,0 +1,139 @@
+package batch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+)
+
+func createFiles(t *testing.T, dir string, names ...string) {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFilesWalksDirectoriesWhenRecursive(t *testing.T) {
+	dir := t.TempDir()
+	createFiles(t, dir, "a", "sub/b", "sub/deeper/c")
+
+	files, failures := Files([]string{dir}, true)
+	if len(failures) != 0 {
+		t.Errorf("Expected no failures, got %v", failures)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "a"),
+		filepath.Join(dir, "sub", "b"),
+		filepath.Join(dir, "sub", "deeper", "c"),
+	}
+
+	sort.Strings(files)
+	if !reflect.DeepEqual(expected, files) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+}
+
+func TestFilesReportsBadPathsWithoutAborting(t *testing.T) {
+	dir := t.TempDir()
+	createFiles(t, dir, "a", "sub/b")
+
+	missing := filepath.Join(dir, "missing")
+	files, failures := Files([]string{missing, filepath.Join(dir, "sub"), filepath.Join(dir, "a")}, false)
+
+	if expected := []string{filepath.Join(dir, "a")}; !reflect.DeepEqual(expected, files) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+
+	if len(failures) != 2 {
+		t.Fatalf("Expected %d failures, got %d", 2, len(failures))
+	}
+
+	if failures[0].Input != missing || !errors.Is(failures[0].Err, os.ErrNotExist) {
+		t.Errorf("Expected %s to not exist, got %v", missing, failures[0])
+	}
+
+	if !errors.Is(failures[1].Err, ErrIsDirectory) {
+		t.Errorf("Expected %s, got %v", ErrIsDirectory, failures[1].Err)
+	}
+}
+
+func TestRunReturnsResultsInOrderAndContinuesOnFailure(t *testing.T) {
+	files := []string{"a", "b", "c", "d", "e", "f"}
+	failure := errors.New("failure")
+
+	var running, maxRunning int32
+
+	results := Run(files, 2, func(file string) Result {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+
+		if file == "b" {
+			return Result{Input: file, Err: failure}
+		}
+
+		return Result{Input: file, InputSize: 10, OutputSize: 5}
+	})
+
+	if maxRunning > 2 {
+		t.Errorf("Expected at most %d concurrent jobs, got %d", 2, maxRunning)
+	}
+
+	for i, result := range results {
+		if result.Input != files[i] {
+			t.Errorf("Expected %s, got %s", files[i], result.Input)
+		}
+	}
+
+	expected := Summary{Files: 6, Failed: 1, InputSize: 50, OutputSize: 25}
+	if actual := Summarize(results); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestRunWithoutFiles(t *testing.T) {
+	results := Run(nil, 4, func(file string) Result {
+		t.Errorf("Expected work not to be called, got %s", file)
+		return Result{}
+	})
+
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %v", results)
+	}
+}
+
+func TestRunRecoversFromPanic(t *testing.T) {
+	results := Run([]string{"a", "b"}, 1, func(file string) Result {
+		if file == "a" {
+			panic("corrupt input")
+		}
+
+		return Result{Input: file}
+	})
+
+	if !errors.Is(results[0].Err, ErrPanic) {
+		t.Errorf("Expected %s, got %v", ErrPanic, results[0].Err)
+	}
+
+	if results[1].Err != nil {
+		t.Errorf("Expected nil error, got %s", results[1].Err)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mjjs/gompressor/benchmark"
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/fileio"
+)
+
+func runBench(name string, args []string) int {
+	flags := newFlagSet(name)
+	codecFlags := addCodecFlags(flags, "")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	c, err := codecFlags.codec()
+	if err != nil {
+		return fail(err)
+	}
+
+	codecs := []codec.Codec{c}
+
+	if c == nil {
+		codecs = nil
+
+		for _, registration := range codec.Registrations() {
+			c, err := registration.New(nil)
+			if err != nil {
+				return fail(err)
+			}
+
+			codecs = append(codecs, c)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "file\talgorithm\tsize\tcompressed\tratio\tcompress\tdecompress\tcorrect\t")
+
+	exitCode := 0
+
+	for _, file := range flags.Args() {
+		data, err := fileio.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", file, err)
+			exitCode = 1
+
+			continue
+		}
+
+		for _, c := range codecs {
+			result, err := benchmark.Run(context.Background(), data, c, nil)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s: %s\n", file, c.Algorithm(), err)
+				exitCode = 1
+
+				continue
+			}
+
+			if !result.OK {
+				exitCode = 1
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%.2f%%\t%s\t%s\t%t\t\n",
+				file, c.Algorithm(), data.Size(), result.CompressedSize,
+				result.Ratio(),
+				throughput(data.Size(), result.CompressTime),
+				throughput(data.Size(), result.DecompressTime),
+				result.OK)
+		}
+	}
+
+	w.Flush()
+
+	return exitCode
+}
+
+// throughput returns the speed of processing size bytes in the given time.
+func throughput(size int, elapsed time.Duration) string {
+	if elapsed <= 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.2f MB/s", float64(size)/elapsed.Seconds()/1e6)
+}
@@ -0,0 +1,117 @@
+// Package benchmark measures the compression ratio and speed of the codecs.
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/limit"
+	"github.com/mjjs/gompressor/progress"
+)
+
+// Result is the result of compressing and decompressing data with a codec.
+type Result struct {
+	OriginalSize   int
+	CompressedSize int
+	CompressTime   time.Duration
+	DecompressTime time.Duration
+	// OK reports whether the decompressed data equals the original data.
+	OK bool
+}
+
+// Ratio returns the compressed size as a percentage of the original size.
+func (r Result) Ratio() float64 {
+	if r.OriginalSize == 0 {
+		return 0
+	}
+
+	return float64(r.CompressedSize) / float64(r.OriginalSize) * 100
+}
+
+// Candidate is a codec configured with a set of options.
+type Candidate struct {
+	// Name is the name of the codec followed by its options, such as
+	// "lzw dict-size=m".
+	Name  string
+	Codec codec.Codec
+}
+
+// Run compresses and decompresses data with the codec, and checks that the
+// decompressed data equals the original. The progress of the compression is
+// reported to report, and the context cancels both the compression and the
+// decompression. The decompression stops as soon as the data grows larger
+// than the original, which is reported as a failed round trip.
+func Run(ctx context.Context, data *vector.Vector[byte], c codec.Codec, report progress.Func) (Result, error) {
+	start := time.Now()
+
+	compressed, err := codec.CompressContext(ctx, c, data, report)
+	if err != nil {
+		return Result{}, err
+	}
+
+	compressTime := time.Since(start)
+	start = time.Now()
+
+	result := Result{
+		OriginalSize:   data.Size(),
+		CompressedSize: compressed.Size(),
+		CompressTime:   compressTime,
+	}
+
+	decompressed, err := codec.DecompressContext(ctx, c, compressed, limit.Limits{MaxOutput: data.Size()})
+	if errors.Is(err, limit.ErrExceeded) {
+		result.DecompressTime = time.Since(start)
+		return result, nil
+	}
+
+	if err != nil {
+		return Result{}, err
+	}
+
+	result.DecompressTime = time.Since(start)
+	result.OK = bytes.Equal(data.Slice(), decompressed.Slice())
+
+	return result, nil
+}
+
+// Candidates returns a candidate for every registered codec with the default
+// options, and one for each value of the options accepting a fixed set of
+// values, such as each dictionary size of LZW.
+func Candidates() ([]Candidate, error) {
+	var candidates []Candidate
+
+	for _, registration := range codec.Registrations() {
+		variants := 0
+
+		for _, option := range registration.Options {
+			for _, value := range option.Values {
+				c, err := registration.New(map[string]string{option.Name: value})
+				if err != nil {
+					return nil, err
+				}
+
+				name := fmt.Sprintf("%s %s=%s", registration.Name, option.Name, value)
+				candidates = append(candidates, Candidate{Name: name, Codec: c})
+				variants++
+			}
+		}
+
+		if variants > 0 {
+			continue
+		}
+
+		c, err := registration.New(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates = append(candidates, Candidate{Name: registration.Name, Codec: c})
+	}
+
+	return candidates, nil
+}
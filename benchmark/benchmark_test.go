@@ -0,0 +1,120 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/datastructure/vector"
+)
+
+func TestRun(t *testing.T) {
+	data := vector.FromSlice([]byte("TOBEORNOTTOBEORTOBEORNOTTOBEORNOTTOBEORTOBEORNOT"))
+
+	candidates, err := Candidates()
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	for _, candidate := range candidates {
+		result, err := Run(context.Background(), data, candidate.Codec, nil)
+		if err != nil {
+			t.Errorf("%s: Expected nil error, got %s", candidate.Name, err)
+			continue
+		}
+
+		if !result.OK {
+			t.Errorf("%s: Expected the round trip to succeed", candidate.Name)
+		}
+
+		if result.OriginalSize != data.Size() {
+			t.Errorf("%s: Expected original size %d, got %d", candidate.Name, data.Size(), result.OriginalSize)
+		}
+
+		if result.CompressedSize == 0 {
+			t.Errorf("%s: Expected a compressed size", candidate.Name)
+		}
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c, err := codec.New("lzw", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := vector.FromSlice(make([]byte, 1<<20))
+
+	if _, err := Run(ctx, data, c, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %s, got %v", context.Canceled, err)
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	candidates, err := Candidates()
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	expected := []string{
+		"huffman",
+		"lzw dict-size=xs",
+		"lzw dict-size=s",
+		"lzw dict-size=m",
+		"lzw dict-size=l",
+		"lzw dict-size=xl",
+	}
+
+	if len(candidates) != len(expected) {
+		t.Fatalf("Expected %d candidates, got %d", len(expected), len(candidates))
+	}
+
+	for i, candidate := range candidates {
+		if candidate.Name != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], candidate.Name)
+		}
+	}
+}
+
+func TestRatio(t *testing.T) {
+	testCases := []struct {
+		result   Result
+		expected float64
+	}{
+		{result: Result{OriginalSize: 200, CompressedSize: 50}, expected: 25},
+		{result: Result{OriginalSize: 0, CompressedSize: 0}, expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		if actual := testCase.result.Ratio(); actual != testCase.expected {
+			t.Errorf("Expected %v, got %v", testCase.expected, actual)
+		}
+	}
+}
+
+func TestRunCancelledDuringDecompression(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := codec.New("huffman", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := vector.FromSlice([]byte("TOBEORNOTTOBEORTOBEORNOT"))
+
+	// The context is cancelled once all of the data has been compressed.
+	_, err = Run(ctx, data, c, func(read int, written int) {
+		if read == data.Size() {
+			cancel()
+		}
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %s, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,244 @@
+// Package bitio implements reading and writing of individual bits and
+// variable length bit fields on top of byte oriented readers and writers.
+package bitio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Order determines in which order the bits are packed into bytes.
+type Order int
+
+const (
+	// MSBFirst fills each byte starting from its most significant bit, and
+	// writes multi-bit values starting from their most significant bit.
+	MSBFirst Order = iota
+	// LSBFirst fills each byte starting from its least significant bit, and
+	// writes multi-bit values starting from their least significant bit.
+	LSBFirst
+)
+
+// MaxBits is the largest number of bits that can be read or written at once.
+const MaxBits uint = 64
+
+// ErrInvalidBitCount is returned when attempting to read or write more than
+// MaxBits bits at once.
+var ErrInvalidBitCount = errors.New("invalid bit count")
+
+type flusher interface {
+	Flush() error
+}
+
+// BitWriter writes bits to an underlying io.Writer. Bits are buffered until
+// a full byte has been written, so Flush must be called after the last write.
+type BitWriter struct {
+	w       io.ByteWriter
+	flusher flusher
+	order   Order
+	current byte
+	nBits   uint
+	written uint64
+}
+
+// NewBitWriter returns a BitWriter which writes to w in the given bit order.
+// If w does not implement io.ByteWriter, the writes are buffered, and the
+// buffer is written to w on Flush.
+func NewBitWriter(w io.Writer, order Order) *BitWriter {
+	bw := &BitWriter{order: order}
+
+	if byteWriter, ok := w.(io.ByteWriter); ok {
+		bw.w = byteWriter
+	} else {
+		buffered := bufio.NewWriter(w)
+		bw.w = buffered
+		bw.flusher = buffered
+	}
+
+	return bw
+}
+
+// WriteBit writes a single bit. Any non-zero value is written as 1.
+func (w *BitWriter) WriteBit(bit uint8) error {
+	if bit != 0 {
+		bit = 1
+	}
+
+	return w.WriteBits(uint64(bit), 1)
+}
+
+// WriteBits writes the n lowest bits of value. At most MaxBits bits can be
+// written at once.
+func (w *BitWriter) WriteBits(value uint64, n uint) error {
+	if n > MaxBits {
+		return fmt.Errorf("%w: %d", ErrInvalidBitCount, n)
+	}
+
+	for n > 0 {
+		free := 8 - w.nBits
+		take := minUint(free, n)
+		mask := uint64(1)<<take - 1
+
+		if w.order == MSBFirst {
+			chunk := byte((value >> (n - take)) & mask)
+			w.current |= chunk << (free - take)
+		} else {
+			chunk := byte(value & mask)
+			w.current |= chunk << w.nBits
+			value >>= take
+		}
+
+		n -= take
+		w.nBits += take
+		w.written += uint64(take)
+
+		if w.nBits == 8 {
+			if err := w.writeCurrent(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Align pads the current byte with zero bits so that the next write starts
+// from a byte boundary. Align does nothing if the writer is already aligned.
+func (w *BitWriter) Align() error {
+	if w.nBits == 0 {
+		return nil
+	}
+
+	return w.writeCurrent()
+}
+
+// Flush aligns the writer to a byte boundary and writes any buffered data to
+// the underlying io.Writer.
+func (w *BitWriter) Flush() error {
+	if err := w.Align(); err != nil {
+		return err
+	}
+
+	if w.flusher != nil {
+		return w.flusher.Flush()
+	}
+
+	return nil
+}
+
+// BitsWritten returns the number of bits written so far, excluding any
+// padding added by Align or Flush.
+func (w *BitWriter) BitsWritten() uint64 {
+	return w.written
+}
+
+func (w *BitWriter) writeCurrent() error {
+	err := w.w.WriteByte(w.current)
+
+	w.current = 0
+	w.nBits = 0
+
+	return err
+}
+
+// BitReader reads bits from an underlying io.Reader.
+type BitReader struct {
+	r       io.ByteReader
+	order   Order
+	current byte
+	nBits   uint
+	read    uint64
+}
+
+// NewBitReader returns a BitReader which reads from r in the given bit order.
+// If r does not implement io.ByteReader, the reads are buffered and the
+// BitReader may read more data from r than needed.
+func NewBitReader(r io.Reader, order Order) *BitReader {
+	br := &BitReader{order: order}
+
+	if byteReader, ok := r.(io.ByteReader); ok {
+		br.r = byteReader
+	} else {
+		br.r = bufio.NewReader(r)
+	}
+
+	return br
+}
+
+// ReadBit reads a single bit.
+func (r *BitReader) ReadBit() (uint8, error) {
+	bit, err := r.ReadBits(1)
+	return uint8(bit), err
+}
+
+// ReadBits reads n bits and returns them as the lowest bits of the result.
+// At most MaxBits bits can be read at once. io.EOF is returned if no bits
+// could be read, and io.ErrUnexpectedEOF if the input ends in the middle of
+// the value.
+func (r *BitReader) ReadBits(n uint) (uint64, error) {
+	if n > MaxBits {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidBitCount, n)
+	}
+
+	var (
+		value uint64
+		shift uint
+		total = n
+	)
+
+	for n > 0 {
+		if r.nBits == 0 {
+			b, err := r.r.ReadByte()
+			if err != nil {
+				if errors.Is(err, io.EOF) && n != total {
+					return 0, io.ErrUnexpectedEOF
+				}
+
+				return 0, err
+			}
+
+			r.current = b
+			r.nBits = 8
+		}
+
+		take := minUint(r.nBits, n)
+		mask := byte(0xFF >> (8 - take))
+
+		if r.order == MSBFirst {
+			chunk := (r.current >> (r.nBits - take)) & mask
+			value = value<<take | uint64(chunk)
+		} else {
+			chunk := (r.current >> (8 - r.nBits)) & mask
+			value |= uint64(chunk) << shift
+			shift += take
+		}
+
+		n -= take
+		r.nBits -= take
+		r.read += uint64(take)
+	}
+
+	return value, nil
+}
+
+// Align discards the remaining bits of the current byte so that the next read
+// starts from a byte boundary.
+func (r *BitReader) Align() {
+	r.nBits = 0
+}
+
+// BitsRead returns the number of bits read so far, excluding any bits
+// discarded by Align.
+func (r *BitReader) BitsRead() uint64 {
+	return r.read
+}
+
+func minUint(a, b uint) uint {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,139 @@
+package bitio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type writeOnly struct {
+	w io.Writer
+}
+
+func (w writeOnly) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+func TestWriteBitsPacksInOrder(t *testing.T) {
+	testCases := []struct {
+		name     string
+		order    Order
+		expected []byte
+	}{
+		{name: "MSB first", order: MSBFirst, expected: []byte{0b10111001, 0b10000000}},
+		{name: "LSB first", order: LSBFirst, expected: []byte{0b00110111, 0b00000001}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			w := NewBitWriter(buf, testCase.order)
+
+			w.WriteBit(1)
+			w.WriteBits(0b011, 3)
+			w.WriteBits(0b10011, 5)
+
+			if err := w.Flush(); err != nil {
+				t.Errorf("Expected nil error, got %s", err)
+			}
+
+			if !bytes.Equal(buf.Bytes(), testCase.expected) {
+				t.Errorf("Expected %08b, got %08b", testCase.expected, buf.Bytes())
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	values := []struct {
+		value uint64
+		n     uint
+	}{
+		{value: 1, n: 1},
+		{value: 0x1FF, n: 9},
+		{value: 0, n: 3},
+		{value: 0xFFFFFFFFFFFFFFFF, n: 64},
+		{value: 0xABCD, n: 16},
+		{value: 0x123456789, n: 37},
+	}
+
+	for _, order := range []Order{MSBFirst, LSBFirst} {
+		buf := new(bytes.Buffer)
+		w := NewBitWriter(writeOnly{buf}, order)
+
+		for _, v := range values {
+			if err := w.WriteBits(v.value, v.n); err != nil {
+				t.Errorf("Expected nil error, got %s", err)
+			}
+		}
+
+		if err := w.Flush(); err != nil {
+			t.Errorf("Expected nil error, got %s", err)
+		}
+
+		r := NewBitReader(buf, order)
+
+		for _, v := range values {
+			actual, err := r.ReadBits(v.n)
+			if err != nil {
+				t.Errorf("Expected nil error, got %s", err)
+			}
+
+			if actual != v.value {
+				t.Errorf("Expected %x, got %x", v.value, actual)
+			}
+		}
+	}
+}
+
+func TestAlign(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewBitWriter(buf, MSBFirst)
+
+	w.WriteBits(0b101, 3)
+	w.Align()
+	w.Align()
+	w.WriteBits(0xFF, 8)
+	w.Flush()
+
+	if expected := []byte{0b10100000, 0xFF}; !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("Expected %08b, got %08b", expected, buf.Bytes())
+	}
+
+	if n := w.BitsWritten(); n != 11 {
+		t.Errorf("Expected %d bits written, got %d", 11, n)
+	}
+
+	r := NewBitReader(buf, MSBFirst)
+	r.ReadBits(3)
+	r.Align()
+
+	if v, _ := r.ReadBits(8); v != 0xFF {
+		t.Errorf("Expected %x, got %x", 0xFF, v)
+	}
+}
+
+func TestTooManyBits(t *testing.T) {
+	w := NewBitWriter(new(bytes.Buffer), MSBFirst)
+	if err := w.WriteBits(0, 65); !errors.Is(err, ErrInvalidBitCount) {
+		t.Errorf("Expected %s, got %v", ErrInvalidBitCount, err)
+	}
+
+	r := NewBitReader(bytes.NewReader(nil), MSBFirst)
+	if _, err := r.ReadBits(65); !errors.Is(err, ErrInvalidBitCount) {
+		t.Errorf("Expected %s, got %v", ErrInvalidBitCount, err)
+	}
+}
+
+func TestReadPastEnd(t *testing.T) {
+	r := NewBitReader(bytes.NewReader([]byte{0xFF}), LSBFirst)
+
+	if _, err := r.ReadBits(12); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected %s, got %v", io.ErrUnexpectedEOF, err)
+	}
+
+	if _, err := r.ReadBit(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected %s, got %v", io.EOF, err)
+	}
+}
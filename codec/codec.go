@@ -0,0 +1,255 @@
+// Package codec provides a registry of the compression algorithms, so that
+// the algorithms can be looked up by name and used interchangeably.
+package codec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/limit"
+	"github.com/mjjs/gompressor/progress"
+)
+
+// Algorithm identifies a compression algorithm. The values are stored in the
+// compressed formats, so they must not be changed.
+type Algorithm byte
+
+const (
+	// Huffman compresses the data with Huffman coding.
+	Huffman Algorithm = iota + 1
+	// LZW compresses the data with Lempel-Ziv-Welch.
+	LZW
+)
+
+// ErrInvalidAlgorithm is returned when the algorithm is not registered.
+var ErrInvalidAlgorithm = errors.New("invalid algorithm")
+
+// ErrInvalidOption is returned when an option is not supported by a codec or
+// its value is not valid.
+var ErrInvalidOption = errors.New("invalid option")
+
+// Codec compresses and decompresses bytes with a compression algorithm. A
+// codec must be safe for concurrent use.
+type Codec interface {
+	// Algorithm returns the identifier of the algorithm used by the codec.
+	Algorithm() Algorithm
+	// Compress compresses the data.
+	Compress(data *vector.Vector[byte]) (*vector.Vector[byte], error)
+	// Decompress decompresses data compressed by a codec of the same algorithm.
+	Decompress(data *vector.Vector[byte]) (*vector.Vector[byte], error)
+}
+
+// ContextCompressor is implemented by codecs which can be cancelled and report
+// their progress while compressing.
+type ContextCompressor interface {
+	// CompressContext compresses like Compress, but stops with the error of
+	// the context when it is done, and reports the progress to report, which
+	// may be nil.
+	CompressContext(ctx context.Context, data *vector.Vector[byte], report progress.Func) (*vector.Vector[byte], error)
+}
+
+// CompressContext compresses the data with the codec. Codecs which do not
+// implement ContextCompressor can not be cancelled once started, and report
+// their progress only when done.
+func CompressContext(ctx context.Context, c Codec, data *vector.Vector[byte], report progress.Func) (*vector.Vector[byte], error) {
+	if compressor, ok := c.(ContextCompressor); ok {
+		return compressor.CompressContext(ctx, data, report)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	compressed, err := c.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Report(data.Size(), compressed.Size())
+
+	return compressed, nil
+}
+
+// LimitedDecompressor is implemented by codecs which can stop decompressing as
+// soon as the decompressed data exceeds the limits.
+type LimitedDecompressor interface {
+	// DecompressWithLimits decompresses like Decompress, but returns an error
+	// wrapping limit.ErrExceeded when the decompressed data exceeds the limits.
+	DecompressWithLimits(data *vector.Vector[byte], limits limit.Limits) (*vector.Vector[byte], error)
+}
+
+// DecompressWithLimits decompresses the data with the codec, and returns an
+// error wrapping limit.ErrExceeded if the decompressed data exceeds the
+// limits. Codecs which do not implement LimitedDecompressor are checked only
+// after decompressing all of the data.
+func DecompressWithLimits(c Codec, data *vector.Vector[byte], limits limit.Limits) (*vector.Vector[byte], error) {
+	if limited, ok := c.(LimitedDecompressor); ok {
+		return limited.DecompressWithLimits(data, limits)
+	}
+
+	decompressed, err := c.Decompress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := limits.Check(data.Size(), decompressed.Size()); err != nil {
+		return nil, err
+	}
+
+	return decompressed, nil
+}
+
+// ContextDecompressor is implemented by codecs which can be cancelled while
+// decompressing.
+type ContextDecompressor interface {
+	// DecompressContext decompresses like DecompressWithLimits, but stops with
+	// the error of the context when it is done.
+	DecompressContext(ctx context.Context, data *vector.Vector[byte], limits limit.Limits) (*vector.Vector[byte], error)
+}
+
+// DecompressContext decompresses the data with the codec like
+// DecompressWithLimits. Codecs which do not implement ContextDecompressor can
+// not be cancelled once started.
+func DecompressContext(ctx context.Context, c Codec, data *vector.Vector[byte], limits limit.Limits) (*vector.Vector[byte], error) {
+	if decompressor, ok := c.(ContextDecompressor); ok {
+		return decompressor.DecompressContext(ctx, data, limits)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return DecompressWithLimits(c, data, limits)
+}
+
+// OptionInfo describes an option accepted by a codec.
+type OptionInfo struct {
+	Name        string
+	Description string
+	Default     string
+	// Values lists the accepted values of an option which accepts only a
+	// fixed set of values.
+	Values []string
+}
+
+// Registration describes a codec in the registry.
+type Registration struct {
+	Name        string
+	Algorithm   Algorithm
+	Extension   string
+	Description string
+	Options     []OptionInfo
+	// MaxExpansion is the maximum number of bytes a single compressed byte
+	// can decompress into with any options.
+	MaxExpansion int
+	// New creates a codec with the given options. Only options listed in
+	// Options are passed to New.
+	New func(options map[string]string) (Codec, error)
+}
+
+var registry []Registration
+
+// Register adds a codec into the registry. Register panics if a codec with the
+// same name or algorithm has already been registered.
+func Register(registration Registration) {
+	for _, r := range registry {
+		if r.Name == registration.Name || r.Algorithm == registration.Algorithm {
+			panic(fmt.Sprintf("codec %s registered twice", registration.Name))
+		}
+	}
+
+	registry = append(registry, registration)
+	sort.Slice(registry, func(i, j int) bool {
+		return registry[i].Name < registry[j].Name
+	})
+}
+
+// Registrations returns all the registered codecs sorted by name.
+func Registrations() []Registration {
+	return append([]Registration{}, registry...)
+}
+
+// Lookup returns the registration of the codec with the given name.
+func Lookup(name string) (Registration, bool) {
+	for _, r := range registry {
+		if r.Name == name {
+			return r, true
+		}
+	}
+
+	return Registration{}, false
+}
+
+// LookupAlgorithm returns the registration of the codec of the algorithm.
+func LookupAlgorithm(algorithm Algorithm) (Registration, bool) {
+	for _, r := range registry {
+		if r.Algorithm == algorithm {
+			return r, true
+		}
+	}
+
+	return Registration{}, false
+}
+
+// New creates the codec with the given name and options.
+func New(name string, options map[string]string) (Codec, error) {
+	registration, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAlgorithm, name)
+	}
+
+	for option := range options {
+		if !registration.hasOption(option) {
+			return nil, fmt.Errorf("%w: %s does not support %s", ErrInvalidOption, name, option)
+		}
+	}
+
+	return registration.New(options)
+}
+
+// ForAlgorithm creates the codec of the algorithm with the default options.
+func ForAlgorithm(algorithm Algorithm) (Codec, error) {
+	registration, ok := LookupAlgorithm(algorithm)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidAlgorithm, algorithm)
+	}
+
+	return registration.New(nil)
+}
+
+// Valid reports whether the algorithm is registered.
+func (a Algorithm) Valid() bool {
+	_, ok := LookupAlgorithm(a)
+	return ok
+}
+
+func (a Algorithm) String() string {
+	if registration, ok := LookupAlgorithm(a); ok {
+		return registration.Name
+	}
+
+	return fmt.Sprintf("unknown(%d)", byte(a))
+}
+
+// Decompress decompresses the data with the codec of the algorithm.
+func Decompress(data *vector.Vector[byte], algorithm Algorithm) (*vector.Vector[byte], error) {
+	c, err := ForAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Decompress(data)
+}
+
+func (r Registration) hasOption(name string) bool {
+	for _, option := range r.Options {
+		if option.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
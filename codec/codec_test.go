@@ -0,0 +1,272 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mjjs/gompressor/algorithm/lzw"
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/limit"
+)
+
+func TestDecompressedEqualsOriginal(t *testing.T) {
+	inputs := []string{"", "a", "TOBEORNOTTOBEORTOBEORNOT#"}
+
+	for _, registration := range Registrations() {
+		c, err := New(registration.Name, nil)
+		if err != nil {
+			t.Fatalf("%s: Expected nil error, got %s", registration.Name, err)
+		}
+
+		for _, input := range inputs {
+			data := vector.New[byte]()
+			data.Append([]byte(input)...)
+
+			compressed, err := c.Compress(data)
+			if err != nil {
+				t.Errorf("%s: Expected nil error, got %s", registration.Name, err)
+				continue
+			}
+
+			decompressed, err := Decompress(compressed, c.Algorithm())
+			if err != nil {
+				t.Errorf("%s: Expected nil error, got %s", registration.Name, err)
+				continue
+			}
+
+			if !bytes.Equal(data.Slice(), decompressed.Slice()) {
+				t.Errorf("%s: Expected %q, got %q", registration.Name, input, decompressed.Slice())
+			}
+		}
+	}
+}
+
+// unlimitedCodec hides the CompressContext, DecompressWithLimits and
+// DecompressContext methods of the codec.
+type unlimitedCodec struct {
+	codec Codec
+}
+
+func (c unlimitedCodec) Algorithm() Algorithm {
+	return c.codec.Algorithm()
+}
+
+func (c unlimitedCodec) Compress(data *vector.Vector[byte]) (*vector.Vector[byte], error) {
+	return c.codec.Compress(data)
+}
+
+func (c unlimitedCodec) Decompress(data *vector.Vector[byte]) (*vector.Vector[byte], error) {
+	return c.codec.Decompress(data)
+}
+
+func TestDecompressWithLimits(t *testing.T) {
+	data := vector.New[byte]().AppendToCopy(bytes.Repeat([]byte("TOBEORNOT"), 100)...)
+
+	for _, registration := range Registrations() {
+		c, _ := New(registration.Name, nil)
+		compressed, _ := c.Compress(data)
+
+		for _, codec := range []Codec{c, unlimitedCodec{c}} {
+			if _, err := DecompressWithLimits(codec, compressed, limit.Limits{MaxOutput: 899}); !errors.Is(err, limit.ErrExceeded) {
+				t.Errorf("%s: Expected %s, got %v", registration.Name, limit.ErrExceeded, err)
+			}
+
+			decompressed, err := DecompressWithLimits(codec, compressed, limit.Limits{MaxOutput: 900})
+			if err != nil {
+				t.Errorf("%s: Expected nil error, got %s", registration.Name, err)
+				continue
+			}
+
+			if !bytes.Equal(data.Slice(), decompressed.Slice()) {
+				t.Errorf("%s: Expected %q, got %q", registration.Name, data.Slice(), decompressed.Slice())
+			}
+		}
+	}
+}
+
+func TestCompressContext(t *testing.T) {
+	data := vector.New[byte]().AppendToCopy(bytes.Repeat([]byte("TOBEORNOT"), 100)...)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, registration := range Registrations() {
+		c, _ := New(registration.Name, nil)
+
+		for _, codec := range []Codec{c, unlimitedCodec{c}} {
+			if _, err := CompressContext(cancelled, codec, data, nil); !errors.Is(err, context.Canceled) {
+				t.Errorf("%s: Expected %s, got %v", registration.Name, context.Canceled, err)
+			}
+
+			in, out := 0, 0
+			compressed, err := CompressContext(context.Background(), codec, data, func(read int, written int) {
+				in, out = read, written
+			})
+			if err != nil {
+				t.Errorf("%s: Expected nil error, got %s", registration.Name, err)
+				continue
+			}
+
+			if in != data.Size() || out != compressed.Size() {
+				t.Errorf("%s: Expected progress %d -> %d, got %d -> %d",
+					registration.Name, data.Size(), compressed.Size(), in, out)
+			}
+		}
+	}
+}
+
+func TestDecompressContext(t *testing.T) {
+	data := vector.New[byte]().AppendToCopy(bytes.Repeat([]byte("TOBEORNOT"), 100)...)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, registration := range Registrations() {
+		c, _ := New(registration.Name, nil)
+		compressed, _ := c.Compress(data)
+
+		for _, codec := range []Codec{c, unlimitedCodec{c}} {
+			if _, err := DecompressContext(cancelled, codec, compressed, limit.Limits{}); !errors.Is(err, context.Canceled) {
+				t.Errorf("%s: Expected %s, got %v", registration.Name, context.Canceled, err)
+			}
+
+			if _, err := DecompressContext(context.Background(), codec, compressed, limit.Limits{MaxOutput: 899}); !errors.Is(err, limit.ErrExceeded) {
+				t.Errorf("%s: Expected %s, got %v", registration.Name, limit.ErrExceeded, err)
+			}
+
+			decompressed, err := DecompressContext(context.Background(), codec, compressed, limit.Limits{})
+			if err != nil {
+				t.Errorf("%s: Expected nil error, got %s", registration.Name, err)
+				continue
+			}
+
+			if !bytes.Equal(data.Slice(), decompressed.Slice()) {
+				t.Errorf("%s: Expected %q, got %q", registration.Name, data.Slice(), decompressed.Slice())
+			}
+		}
+	}
+}
+
+func TestRegistryContainsAlgorithms(t *testing.T) {
+	testCases := []struct {
+		name      string
+		algorithm Algorithm
+		extension string
+	}{
+		{name: "huffman", algorithm: Huffman, extension: ".huff"},
+		{name: "lzw", algorithm: LZW, extension: ".lzw"},
+	}
+
+	for _, testCase := range testCases {
+		registration, ok := Lookup(testCase.name)
+		if !ok {
+			t.Errorf("Expected %s to be registered", testCase.name)
+			continue
+		}
+
+		if registration.Algorithm != testCase.algorithm || registration.Extension != testCase.extension {
+			t.Errorf("Unexpected registration %+v", registration)
+		}
+
+		if byAlgorithm, _ := LookupAlgorithm(testCase.algorithm); byAlgorithm.Name != testCase.name {
+			t.Errorf("Expected %s, got %s", testCase.name, byAlgorithm.Name)
+		}
+
+		if actual := testCase.algorithm.String(); actual != testCase.name {
+			t.Errorf("Expected %s, got %s", testCase.name, actual)
+		}
+	}
+}
+
+func TestRegisterPanicsOnDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Register to panic")
+		}
+	}()
+
+	Register(Registration{Name: "huffman", Algorithm: 42})
+}
+
+func TestLZWDictionarySizeOption(t *testing.T) {
+	data := vector.New[byte]()
+	data.Append([]byte("TOBEORNOTTOBEORTOBEORNOT#")...)
+
+	for _, value := range []string{"xs", "XS", "512"} {
+		c, err := New("lzw", map[string]string{"dict-size": value})
+		if err != nil {
+			t.Errorf("%s: Expected nil error, got %s", value, err)
+			continue
+		}
+
+		compressed, _ := c.Compress(data)
+		if first := uint16(compressed.MustGet(0))<<8 | uint16(compressed.MustGet(1)); first != uint16(lzw.XS) {
+			t.Errorf("%s: Expected the first code to be %d, got %d", value, lzw.XS, first)
+		}
+	}
+}
+
+func TestOptionValuesAreAccepted(t *testing.T) {
+	for _, registration := range Registrations() {
+		for _, option := range registration.Options {
+			for _, value := range option.Values {
+				if _, err := New(registration.Name, map[string]string{option.Name: value}); err != nil {
+					t.Errorf("%s %s=%s: Expected nil error, got %s", registration.Name, option.Name, value, err)
+				}
+			}
+		}
+	}
+}
+
+func TestMaxExpansionBoundsRepetitiveData(t *testing.T) {
+	input := vector.New[byte]()
+	input.Append(make([]byte, 1<<16)...)
+
+	for _, registration := range Registrations() {
+		c, _ := New(registration.Name, nil)
+
+		compressed, err := c.Compress(input)
+		if err != nil {
+			t.Errorf("%s: Expected nil error, got %s", registration.Name, err)
+			continue
+		}
+
+		if maximum := compressed.Size() * registration.MaxExpansion; input.Size() > maximum {
+			t.Errorf("%s: Expected at most %d bytes per byte, got %d bytes from %d",
+				registration.Name, registration.MaxExpansion, input.Size(), compressed.Size())
+		}
+	}
+}
+
+func TestNewReturnsErrorOnInvalidArguments(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  map[string]string
+		expected error
+	}{
+		{name: "deflate", expected: ErrInvalidAlgorithm},
+		{name: "huffman", options: map[string]string{"dict-size": "xs"}, expected: ErrInvalidOption},
+		{name: "lzw", options: map[string]string{"dict-size": "1000"}, expected: ErrInvalidOption},
+		{name: "lzw", options: map[string]string{"level": "9"}, expected: ErrInvalidOption},
+	}
+
+	for _, testCase := range testCases {
+		if _, err := New(testCase.name, testCase.options); !errors.Is(err, testCase.expected) {
+			t.Errorf("%s %v: Expected %s, got %v", testCase.name, testCase.options, testCase.expected, err)
+		}
+	}
+}
+
+func TestInvalidAlgorithm(t *testing.T) {
+	algorithm := Algorithm(0)
+
+	if algorithm.Valid() {
+		t.Errorf("Expected %s not to be valid", algorithm)
+	}
+
+	if _, err := Decompress(vector.New[byte](), algorithm); !errors.Is(err, ErrInvalidAlgorithm) {
+		t.Errorf("Expected %s, got %v", ErrInvalidAlgorithm, err)
+	}
+}
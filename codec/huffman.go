@@ -0,0 +1,49 @@
+package codec
+
+import (
+	"context"
+
+	"github.com/mjjs/gompressor/algorithm/huffman"
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/limit"
+	"github.com/mjjs/gompressor/progress"
+)
+
+func init() {
+	Register(Registration{
+		Name:         "huffman",
+		Algorithm:    Huffman,
+		Extension:    ".huff",
+		Description:  "Huffman coding, fast with a moderate compression ratio",
+		MaxExpansion: huffman.MaxExpansion,
+		New: func(options map[string]string) (Codec, error) {
+			return huffmanCodec{}, nil
+		},
+	})
+}
+
+type huffmanCodec struct{}
+
+func (huffmanCodec) Algorithm() Algorithm {
+	return Huffman
+}
+
+func (c huffmanCodec) Compress(data *vector.Vector[byte]) (*vector.Vector[byte], error) {
+	return c.CompressContext(context.Background(), data, nil)
+}
+
+func (huffmanCodec) CompressContext(ctx context.Context, data *vector.Vector[byte], report progress.Func) (*vector.Vector[byte], error) {
+	return huffman.CompressContext(ctx, data, report)
+}
+
+func (c huffmanCodec) Decompress(data *vector.Vector[byte]) (*vector.Vector[byte], error) {
+	return c.DecompressWithLimits(data, limit.Limits{})
+}
+
+func (c huffmanCodec) DecompressWithLimits(data *vector.Vector[byte], limits limit.Limits) (*vector.Vector[byte], error) {
+	return c.DecompressContext(context.Background(), data, limits)
+}
+
+func (huffmanCodec) DecompressContext(ctx context.Context, data *vector.Vector[byte], limits limit.Limits) (*vector.Vector[byte], error) {
+	return huffman.DecompressContext(ctx, data, limits)
+}
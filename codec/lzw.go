@@ -0,0 +1,100 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/mjjs/gompressor/algorithm/lzw"
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/limit"
+	"github.com/mjjs/gompressor/progress"
+)
+
+func init() {
+	Register(Registration{
+		Name:        "lzw",
+		Algorithm:   LZW,
+		Extension:   ".lzw",
+		Description: "Lempel-Ziv-Welch, a good compression ratio on repetitive data",
+		Options: []OptionInfo{
+			{
+				Name:        "dict-size",
+				Description: "dictionary size: xs (512), s (1023), m (4095), l (32767) or xl (65535)",
+				Default:     "xl",
+				Values:      []string{"xs", "s", "m", "l", "xl"},
+			},
+		},
+		MaxExpansion: lzw.MaxExpansion,
+		New:          newLZWCodec,
+	})
+}
+
+type lzwCodec struct {
+	dictSize lzw.DictionarySize
+}
+
+func newLZWCodec(options map[string]string) (Codec, error) {
+	c := lzwCodec{dictSize: lzw.XL}
+
+	if value, ok := options["dict-size"]; ok {
+		size, err := lzw.ParseDictionarySize(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: dict-size %s", ErrInvalidOption, value)
+		}
+
+		c.dictSize = size
+	}
+
+	return c, nil
+}
+
+func (lzwCodec) Algorithm() Algorithm {
+	return LZW
+}
+
+func (c lzwCodec) Compress(data *vector.Vector[byte]) (*vector.Vector[byte], error) {
+	return c.CompressContext(context.Background(), data, nil)
+}
+
+func (c lzwCodec) CompressContext(ctx context.Context, data *vector.Vector[byte], report progress.Func) (*vector.Vector[byte], error) {
+	if data.Size() == 0 {
+		return vector.New[byte](), nil
+	}
+
+	codes, err := lzw.CompressContext(ctx, data, c.dictSize, report)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := lzw.WriteCodes(buf, codes); err != nil {
+		return nil, err
+	}
+
+	compressed := vector.New[byte](0, uint(buf.Len()))
+	compressed.Append(buf.Bytes()...)
+
+	return compressed, nil
+}
+
+func (c lzwCodec) Decompress(data *vector.Vector[byte]) (*vector.Vector[byte], error) {
+	return c.DecompressWithLimits(data, limit.Limits{})
+}
+
+func (c lzwCodec) DecompressWithLimits(data *vector.Vector[byte], limits limit.Limits) (*vector.Vector[byte], error) {
+	return c.DecompressContext(context.Background(), data, limits)
+}
+
+func (lzwCodec) DecompressContext(ctx context.Context, data *vector.Vector[byte], limits limit.Limits) (*vector.Vector[byte], error) {
+	if data.Size() == 0 {
+		return vector.New[byte](), nil
+	}
+
+	codes, err := lzw.ReadCodes(bytes.NewReader(data.Slice()))
+	if err != nil {
+		return nil, err
+	}
+
+	return lzw.DecompressContext(ctx, codes, limits)
+}
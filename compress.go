@@ -0,0 +1,558 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mjjs/gompressor/batch"
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/fileio"
+	"github.com/mjjs/gompressor/limit"
+	"github.com/mjjs/gompressor/parallel"
+	"github.com/mjjs/gompressor/seekable"
+)
+
+// errUnknownAlgorithm is returned when the algorithm of a file can not be
+// determined from its name or contents.
+var errUnknownAlgorithm = errors.New("unknown algorithm, choose one with -a")
+
+// options holds the settings used for compressing and decompressing files.
+type options struct {
+	// codec is nil when decompressing without a chosen algorithm.
+	codec    codec.Codec
+	threads  int
+	seekable bool
+
+	// force allows overwriting existing output files, and keep keeps the
+	// input files after they have been processed.
+	force bool
+	keep  bool
+
+	// mmap maps the input and output files into memory instead of reading
+	// and writing them.
+	mmap bool
+
+	// progress draws a progress bar of compressing a file into the
+	// standard error.
+	progress bool
+
+	// start and length limit decompression of seekable files to a byte
+	// range. A negative length extends the range to the end of the data.
+	start  int64
+	length int64
+
+	// limits protects decompression against decompression bombs.
+	limits limit.Limits
+}
+
+func runCompress(name string, args []string) int {
+	flags := newFlagSet(name)
+	codecFlags := addCodecFlags(flags, "lzw")
+	outputFlag := flags.String("o", "", "output file, only when compressing a single file, - writes into the standard output")
+	threadsFlag := flags.Int("threads", 1, "number of threads used for block compression, 0 uses all CPUs")
+	seekableFlag := flags.Bool("seekable", false, "compress into the seekable format which supports decompressing byte ranges")
+	jobsFlag := flags.Int("jobs", 0, "number of files processed concurrently, 0 uses all CPUs")
+	recursiveFlag := flags.Bool("r", false, "process the files in the given directories recursively")
+	forceFlag := flags.Bool("f", false, "overwrite existing output files")
+	keepFlag := flags.Bool("k", false, "keep the input files instead of removing them")
+	mmapFlag := flags.Bool("mmap", false, "map the files into memory instead of reading and writing them, if possible")
+	flags.Parse(args)
+
+	c, err := codecFlags.codec()
+	if err != nil {
+		return fail(err)
+	}
+
+	if *threadsFlag < 0 {
+		return fail(fmt.Errorf("the amount of threads can not be negative"))
+	}
+
+	if *seekableFlag && *threadsFlag != 1 {
+		return fail(fmt.Errorf("the seekable format can not be combined with -threads"))
+	}
+
+	files := inputFiles(flags.Args())
+
+	if *outputFlag != "" && (len(files) > 1 || *recursiveFlag) {
+		return fail(fmt.Errorf("-o can only be used with a single file"))
+	}
+
+	// The progress bar would mix with the results of other files.
+	opts := options{
+		codec:    c,
+		threads:  *threadsFlag,
+		seekable: *seekableFlag,
+		force:    *forceFlag,
+		keep:     *keepFlag,
+		mmap:     *mmapFlag,
+		progress: len(files) == 1 && !*recursiveFlag && isTerminal(os.Stderr),
+	}
+	ext := extension(c)
+
+	// Interrupting the compression removes the partially written output.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return processFiles(files, *recursiveFlag, *jobsFlag, func(file string) batch.Result {
+		result := batch.Result{Input: file, Output: *outputFlag}
+
+		if result.Output == "" && file == stdio {
+			result.Output = stdio
+		} else if result.Output == "" && strings.HasSuffix(file, ext) {
+			result.Err = fmt.Errorf("already has %s suffix", ext)
+			return result
+		} else if result.Output == "" {
+			result.Output = file + ext
+		}
+
+		if result.Output == stdio && isTerminal(os.Stdout) {
+			result.Err = fmt.Errorf("compressed data not written to a terminal, use -o or redirect the output")
+			return result
+		}
+
+		if result.Err = checkOutput(file, result.Output, opts.force); result.Err != nil {
+			return result
+		}
+
+		result.InputSize, result.OutputSize, result.Err = compressFile(ctx, file, result.Output, opts)
+		if result.Err == nil {
+			result.Err = finishFile(file, result.Output, opts.keep)
+		}
+
+		return result
+	})
+}
+
+func runDecompress(name string, args []string) int {
+	flags := newFlagSet(name)
+	codecFlags := addCodecFlags(flags, "")
+	outputFlag := flags.String("o", "", "output file, only when decompressing a single file, - writes into the standard output")
+	threadsFlag := flags.Int("threads", 0, "number of threads used for decompressing blocks, 0 uses all CPUs")
+	rangeFlag := flags.String("range", "", "decompress only the byte range start:len of a seekable file")
+	jobsFlag := flags.Int("jobs", 0, "number of files processed concurrently, 0 uses all CPUs")
+	recursiveFlag := flags.Bool("r", false, "process the files in the given directories recursively")
+	forceFlag := flags.Bool("f", false, "overwrite existing output files")
+	keepFlag := flags.Bool("k", false, "keep the input files instead of removing them")
+	mmapFlag := flags.Bool("mmap", false, "map the files into memory instead of reading and writing them, if possible")
+	testFlag := flags.Bool("t", false, "test the integrity of the files without writing anything, like the test command")
+	limitFlags := addLimitFlags(flags)
+	flags.Parse(args)
+
+	c, err := codecFlags.codec()
+	if err != nil {
+		return fail(err)
+	}
+
+	limits, err := limitFlags.limits()
+	if err != nil {
+		return fail(err)
+	}
+
+	files := inputFiles(flags.Args())
+
+	if *testFlag {
+		return testFiles(files, *recursiveFlag, *jobsFlag,
+			options{codec: c, threads: *threadsFlag, length: -1, mmap: *mmapFlag, limits: limits})
+	}
+
+	if *outputFlag != "" && (len(files) > 1 || *recursiveFlag) {
+		return fail(fmt.Errorf("-o can only be used with a single file"))
+	}
+
+	// A range leaves most of the data out, so the input is always kept.
+	opts := options{
+		codec:   c,
+		threads: *threadsFlag,
+		length:  -1,
+		force:   *forceFlag,
+		keep:    *keepFlag || *rangeFlag != "",
+		mmap:    *mmapFlag,
+		limits:  limits,
+	}
+
+	if *rangeFlag != "" {
+		opts.start, opts.length, err = parseRange(*rangeFlag)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	return processFiles(files, *recursiveFlag, *jobsFlag, func(file string) batch.Result {
+		result := batch.Result{Input: file, Output: *outputFlag}
+		fileOpts := opts
+
+		if file == stdio && result.Output == "" {
+			result.Output = stdio
+		} else if file != stdio {
+			var err error
+
+			fileOpts, err = optionsForFile(file, opts)
+			if err != nil {
+				result.Err = err
+				return result
+			}
+		}
+
+		if result.Output == "" {
+			ext := extension(fileOpts.codec)
+
+			if !strings.HasSuffix(file, ext) || len(file) == len(ext) {
+				result.Err = fmt.Errorf("unknown suffix, expected %s", ext)
+				return result
+			}
+
+			result.Output = strings.TrimSuffix(file, ext)
+		}
+
+		if result.Err = checkOutput(file, result.Output, fileOpts.force); result.Err != nil {
+			return result
+		}
+
+		result.InputSize, result.OutputSize, result.Err = decompressFile(file, result.Output, fileOpts)
+		if result.Err == nil {
+			result.Err = finishFile(file, result.Output, fileOpts.keep)
+		}
+
+		return result
+	})
+}
+
+// optionsForFile fills in the codec of the options from the name of the file,
+// if no algorithm was chosen.
+func optionsForFile(filename string, opts options) (options, error) {
+	if opts.codec != nil {
+		return opts, nil
+	}
+
+	c, ok := codecForFile(filename)
+	if !ok {
+		return opts, errUnknownAlgorithm
+	}
+
+	opts.codec = c
+
+	return opts, nil
+}
+
+// checkOutput returns an error if the output file already exists and may not
+// be overwritten, or if it is the input file itself.
+func checkOutput(inputFilename string, outputFilename string, force bool) error {
+	if outputFilename == stdio {
+		return nil
+	}
+
+	outputInfo, err := os.Stat(outputFilename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if inputFilename != stdio {
+		if inputInfo, err := os.Stat(inputFilename); err == nil && os.SameFile(inputInfo, outputInfo) {
+			return fmt.Errorf("%s is the input file", outputFilename)
+		}
+	}
+
+	if !force {
+		return fmt.Errorf("%s already exists, use -f to overwrite it", outputFilename)
+	}
+
+	return nil
+}
+
+// finishFile copies the permissions and the modification time of the input
+// file onto the output file, and removes the input file unless it is kept.
+// Nothing is done when either of the files is a standard stream.
+func finishFile(inputFilename string, outputFilename string, keep bool) error {
+	if inputFilename == stdio || outputFilename == stdio {
+		return nil
+	}
+
+	info, err := os.Stat(inputFilename)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chmod(outputFilename, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	if err := os.Chtimes(outputFilename, time.Now(), info.ModTime()); err != nil {
+		return err
+	}
+
+	if keep {
+		return nil
+	}
+
+	return os.Remove(inputFilename)
+}
+
+// parseRange parses a byte range in the form start:len. The length can be
+// left out to extend the range to the end of the data.
+func parseRange(s string) (int64, int64, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %q, expected start:len", s)
+	}
+
+	start, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("invalid range start %q", parts[0])
+	}
+
+	if parts[1] == "" {
+		return start, -1, nil
+	}
+
+	length, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || length < 0 {
+		return 0, 0, fmt.Errorf("invalid range length %q", parts[1])
+	}
+
+	return start, length, nil
+}
+
+// compressFile compresses the input file into the output file, and returns the
+// amount of bytes read and written. The file is compressed in blocks when
+// threads is not 1. Compression stops when the context is done.
+func compressFile(ctx context.Context, inputFilename string, outputFilename string, opts options) (int, int, error) {
+	if opts.seekable {
+		return compressSeekable(ctx, inputFilename, outputFilename, opts)
+	}
+
+	bytes, release, err := loadInput(inputFilename, opts.mmap)
+	if err != nil {
+		return 0, 0, fmt.Errorf("input file could not be read: %w", err)
+	}
+
+	defer release()
+
+	report, clear := showProgress(inputFilename, bytes.Size(), opts.progress)
+	defer clear()
+
+	var compressed *vector.Vector[byte]
+
+	if opts.threads != 1 {
+		compressed, err = parallel.CompressContext(ctx, bytes, opts.codec, parallel.DefaultBlockSize, opts.threads, report)
+	} else {
+		compressed, err = codec.CompressContext(ctx, opts.codec, bytes, report)
+	}
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not compress data: %w", err)
+	}
+
+	written, err := writeOutput(compressed, outputFilename, opts.mmap)
+
+	return bytes.Size(), written, err
+}
+
+// decompressFile decompresses the input file into the output file, and returns
+// the amount of bytes read and written. Seekable files are decompressed within
+// the range of the options.
+func decompressFile(inputFilename string, outputFilename string, opts options) (int, int, error) {
+	if inputFilename != stdio {
+		header, err := fileio.ReadFileHeader(inputFilename, seekable.MagicSize)
+		if err != nil {
+			return 0, 0, fmt.Errorf("input file could not be read: %w", err)
+		}
+
+		if seekable.IsSeekable(header) {
+			return decompressSeekableFile(inputFilename, outputFilename, opts)
+		}
+	}
+
+	data, release, err := loadInput(inputFilename, opts.mmap)
+	if err != nil {
+		return 0, 0, fmt.Errorf("input file could not be read: %w", err)
+	}
+
+	defer release()
+
+	if seekable.IsSeekable(data) {
+		reader := bytes.NewReader(data.Slice())
+		return decompressSeekable(reader, reader.Size(), outputFilename, opts)
+	}
+
+	if opts.start != 0 || opts.length >= 0 {
+		return 0, 0, fmt.Errorf("not in the seekable format, ranges can not be decompressed")
+	}
+
+	decompressed, err := decompress(data, opts)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not decompress data: %w", err)
+	}
+
+	written, err := writeOutput(decompressed, outputFilename, opts.mmap)
+
+	return data.Size(), written, err
+}
+
+// decompress decompresses data in any of the formats within the limits of the
+// options. Files compressed in blocks are recognized and decompressed using the
+// threads of the options. Other data is decompressed with the codec of the
+// options.
+func decompress(data *vector.Vector[byte], opts options) (*vector.Vector[byte], error) {
+	switch {
+	case seekable.IsSeekable(data):
+		reader, err := seekable.NewReader(bytes.NewReader(data.Slice()), int64(data.Size()))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := opts.limits.Check(data.Size(), int(reader.Size())); err != nil {
+			return nil, err
+		}
+
+		decompressed, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		result := vector.New[byte](0, uint(len(decompressed)))
+		result.Append(decompressed...)
+
+		return result, nil
+
+	case parallel.IsBlockFormat(data):
+		return parallel.DecompressWithLimits(data, opts.threads, opts.limits)
+
+	case opts.codec == nil:
+		return nil, errUnknownAlgorithm
+
+	default:
+		return codec.DecompressWithLimits(opts.codec, data, opts.limits)
+	}
+}
+
+// compressSeekable compresses the input file into the seekable format one block
+// at a time, so the whole file is never held in memory. Compression stops when
+// the context is done.
+func compressSeekable(ctx context.Context, inputFilename string, outputFilename string, opts options) (int, int, error) {
+	input, err := openInput(inputFilename)
+	if err != nil {
+		return 0, 0, fmt.Errorf("input file could not be read: %w", err)
+	}
+
+	defer input.Close()
+
+	total := 0
+	if inputFilename != stdio {
+		if info, err := os.Stat(inputFilename); err == nil {
+			total = int(info.Size())
+		}
+	}
+
+	report, clear := showProgress(inputFilename, total, opts.progress)
+	defer clear()
+
+	output, err := createOutput(outputFilename)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not write data: %w", err)
+	}
+
+	defer output.Close()
+
+	buffered := bufio.NewWriter(output)
+
+	writer, err := seekable.NewWriter(buffered, opts.codec, seekable.DefaultBlockSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not compress data: %w", err)
+	}
+
+	reader := &progressReader{ctx: ctx, r: input, report: func(read int) {
+		report.Report(read, int(writer.BytesWritten()))
+	}}
+
+	read, err := io.Copy(writer, reader)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not compress data: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return 0, 0, fmt.Errorf("could not write data: %w", err)
+	}
+
+	if err := buffered.Flush(); err != nil {
+		return 0, 0, fmt.Errorf("could not write data: %w", err)
+	}
+
+	if err := output.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("could not write data: %w", err)
+	}
+
+	return int(read), int(writer.BytesWritten()), nil
+}
+
+// decompressSeekableFile decompresses the range of the options from the
+// seekable input file without reading the rest of the file.
+func decompressSeekableFile(inputFilename string, outputFilename string, opts options) (int, int, error) {
+	input, err := os.Open(inputFilename)
+	if err != nil {
+		return 0, 0, fmt.Errorf("input file could not be read: %w", err)
+	}
+
+	defer input.Close()
+
+	info, err := input.Stat()
+	if err != nil {
+		return 0, 0, fmt.Errorf("input file could not be read: %w", err)
+	}
+
+	return decompressSeekable(input, info.Size(), outputFilename, opts)
+}
+
+// decompressSeekable decompresses the range of the options from the seekable
+// data of the given size. Only the blocks containing the range are
+// decompressed, and the limits of the options apply to the range.
+func decompressSeekable(input io.ReaderAt, size int64, outputFilename string, opts options) (int, int, error) {
+	reader, err := seekable.NewReader(input, size)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not decompress data: %w", err)
+	}
+
+	start, length := opts.start, opts.length
+
+	if start > reader.Size() {
+		return 0, 0, fmt.Errorf("range start %d is past the end of the data (%d bytes)", start, reader.Size())
+	}
+
+	if length < 0 || length > reader.Size()-start {
+		length = reader.Size() - start
+	}
+
+	if err := opts.limits.Check(int(size), int(length)); err != nil {
+		return 0, 0, fmt.Errorf("could not decompress data: %w", err)
+	}
+
+	output, err := createOutput(outputFilename)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not write data: %w", err)
+	}
+
+	defer output.Close()
+
+	n, err := io.Copy(output, io.NewSectionReader(reader, start, length))
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not decompress data: %w", err)
+	}
+
+	if err := output.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("could not write data: %w", err)
+	}
+
+	return int(size), int(n), nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
 	"flag"
 	"fmt"
@@ -8,10 +9,10 @@ import (
 	"os"
 	"strings"
 	"sync"
-	"time"
 
-	"github.com/mjjs/gompressor/algorithm/huffman"
 	"github.com/mjjs/gompressor/algorithm/lzw"
+	"github.com/mjjs/gompressor/benchmark"
+	"github.com/mjjs/gompressor/codec"
 	"github.com/mjjs/gompressor/datastructure/vector"
 	"github.com/mjjs/gompressor/fileio"
 )
@@ -109,79 +110,59 @@ func main() {
 	writeCSV(huffmanResults, "huffman.csv")
 }
 
-func testLZW(filename string, dictSize lzw.DictionarySize, uncompressed *vector.Vector) testResult {
-	originalSize := uncompressed.Size()
-
-	result := testResult{
-		algorithm:         "LZW",
-		filename:          filename,
-		dictionarySize:    uint16(dictSize),
-		originalSizeBytes: originalSize,
-	}
-
+func testLZW(filename string, dictSize lzw.DictionarySize, uncompressed *vector.Vector[byte]) testResult {
 	log.Printf("Testing LZW compression with dictionary size of %d bytes", dictSize)
-	compressStart := time.Now()
-
-	compressed, err := lzw.CompressWithDictSize(uncompressed, dictSize)
-	if err != nil {
-		panic(fmt.Sprintf("lzw compression failed: %s", err))
-	}
 
-	result.compressTimeMicroseconds = time.Since(compressStart).Microseconds()
-	result.compressedSizeBytes = compressed.Size() * 2 // Codes are 16 bits
-	result.compressRatio = float64(result.compressedSizeBytes) / float64(originalSize) * 100
-
-	decompressStart := time.Now()
-	decompressed, err := lzw.Decompress(compressed)
+	c, err := codec.New("lzw", map[string]string{"dict-size": fmt.Sprint(dictSize)})
 	if err != nil {
-		panic(fmt.Sprintf("lzw decompression failed: %s", err))
+		panic(err)
 	}
 
-	result.decompressTimeMicroseconds = time.Since(decompressStart).Microseconds()
-	result.success = compare(uncompressed, decompressed)
+	result := runTest(filename, "LZW", c, uncompressed)
+	result.dictionarySize = uint16(dictSize)
 
 	return result
 }
 
-func testHuffman(filename string, uncompressed *vector.Vector) testResult {
+func testHuffman(filename string, uncompressed *vector.Vector[byte]) testResult {
 	log.Println("Testing Huffman compression")
-	originalSize := uncompressed.Size()
 
-	result := testResult{
-		algorithm:         "Huffman",
-		filename:          filename,
-		originalSizeBytes: originalSize,
+	c, err := codec.New("huffman", nil)
+	if err != nil {
+		panic(err)
 	}
 
-	compressStart := time.Now()
-	compressed := huffman.Compress(uncompressed)
-
-	result.compressTimeMicroseconds = time.Since(compressStart).Microseconds()
-	result.compressedSizeBytes = compressed.Size()
-	result.compressRatio = float64(result.compressedSizeBytes) / float64(originalSize) * 100
+	return runTest(filename, "Huffman", c, uncompressed)
+}
 
-	decompressStart := time.Now()
-	decompressed, err := huffman.Decompress(compressed)
+func runTest(filename string, algorithm string, c codec.Codec, uncompressed *vector.Vector[byte]) testResult {
+	result, err := benchmark.Run(context.Background(), uncompressed, c, nil)
 	if err != nil {
-		panic(fmt.Sprintf("lzw decompression failed: %s", err))
+		panic(fmt.Sprintf("%s failed: %s", algorithm, err))
 	}
 
-	result.decompressTimeMicroseconds = time.Since(decompressStart).Microseconds()
-	result.success = compare(uncompressed, decompressed)
-
-	return result
+	return testResult{
+		filename:                   filename,
+		algorithm:                  algorithm,
+		originalSizeBytes:          result.OriginalSize,
+		compressedSizeBytes:        result.CompressedSize,
+		compressRatio:              result.Ratio(),
+		compressTimeMicroseconds:   result.CompressTime.Microseconds(),
+		decompressTimeMicroseconds: result.DecompressTime.Microseconds(),
+		success:                    result.OK,
+	}
 }
 
-func readTestFile(fn string) (*vector.Vector, error) {
+func readTestFile(fn string) (*vector.Vector[byte], error) {
 	return fileio.ReadFile(fn)
 }
 
-func getNBytes(from *vector.Vector, n int) *vector.Vector {
+func getNBytes(from *vector.Vector[byte], n int) *vector.Vector[byte] {
 	if n > from.Size() {
 		return from
 	}
 
-	newVector := vector.New(uint(n))
+	newVector := vector.New[byte](uint(n))
 	for i := 0; i < n; i++ {
 		newVector.MustSet(i, from.MustGet(i))
 	}
@@ -189,24 +170,6 @@ func getNBytes(from *vector.Vector, n int) *vector.Vector {
 	return newVector
 }
 
-func compare(a *vector.Vector, b *vector.Vector) bool {
-	if a == nil || b == nil {
-		return false
-	}
-
-	if a.Size() != b.Size() {
-		return false
-	}
-
-	for i := 0; i < a.Size(); i++ {
-		if a.MustGet(i) != b.MustGet(i) {
-			return false
-		}
-	}
-
-	return true
-}
-
 func writeCSV(results []testResult, name string) {
 	headers := []string{"filename", "algorithm", "original size", "compressed size", "compression ratio", "compress time", "decompress time", "dictionary size"}
 	records := [][]string{headers}
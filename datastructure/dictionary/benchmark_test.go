@@ -0,0 +1,140 @@
+package dictionary
+
+import (
+	"os"
+	"testing"
+)
+
+const benchmarkFile string = "../../testdata/world192.txt"
+
+// benchmarkInputSize is the amount of bytes of the benchmark file used.
+const benchmarkInputSize int = 256 * 1024
+
+// lzwDictionarySize mirrors the largest LZW dictionary size, after which the
+// LZW dictionaries are reset.
+const lzwDictionarySize int = 65535
+
+var implementations = []struct {
+	name    string
+	options []Option
+}{
+	{name: "Chaining", options: nil},
+	{name: "OpenAddressing", options: []Option{OpenAddressing()}},
+}
+
+func readBenchmarkInput(b *testing.B) []byte {
+	input, err := os.ReadFile(benchmarkFile)
+	if err != nil {
+		b.Skipf("benchmark input not available: %s", err)
+	}
+
+	if len(input) > benchmarkInputSize {
+		input = input[:benchmarkInputSize]
+	}
+
+	return input
+}
+
+func newCompressDictionary(opts ...Option) *Dictionary[string, uint16] {
+	dict := NewWithSize[string, uint16](StringHasher{}, 256, opts...)
+	for i := 0; i < 256; i++ {
+		dict.Set(string([]byte{byte(i)}), uint16(i))
+	}
+
+	return dict
+}
+
+func newDecompressDictionary(opts ...Option) *Dictionary[uint16, []byte] {
+	dict := NewWithSize[uint16, []byte](IntegerHasher[uint16]{}, 256, opts...)
+	for i := 0; i < 256; i++ {
+		dict.Set(uint16(i), []byte{byte(i)})
+	}
+
+	return dict
+}
+
+// lzwCompress compresses input with LZW using a dictionary created by
+// newDict, and returns the codes.
+func lzwCompress(input []byte, newDict func() *Dictionary[string, uint16]) []uint16 {
+	dict := newDict()
+	codes := make([]uint16, 0, len(input))
+	start := 0
+
+	for i := 1; i < len(input); i++ {
+		if dict.Size() == lzwDictionarySize {
+			dict = newDict()
+		}
+
+		if _, ok := dict.Get(string(input[start : i+1])); ok {
+			continue
+		}
+
+		code, _ := dict.Get(string(input[start:i]))
+		codes = append(codes, code)
+
+		dict.Set(string(input[start:i+1]), uint16(dict.Size()))
+		start = i
+	}
+
+	code, _ := dict.Get(string(input[start:]))
+	return append(codes, code)
+}
+
+// BenchmarkLZWCompress runs the dictionary operations done by LZW compression,
+// which maps strings of bytes to codes.
+func BenchmarkLZWCompress(b *testing.B) {
+	input := readBenchmarkInput(b)
+
+	for _, implementation := range implementations {
+		opts := implementation.options
+
+		b.Run(implementation.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+
+			for n := 0; n < b.N; n++ {
+				lzwCompress(input, func() *Dictionary[string, uint16] {
+					return newCompressDictionary(opts...)
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkLZWDecompress runs the dictionary operations done by LZW
+// decompression, which maps codes to strings of bytes.
+func BenchmarkLZWDecompress(b *testing.B) {
+	input := readBenchmarkInput(b)
+	codes := lzwCompress(input, func() *Dictionary[string, uint16] {
+		return newCompressDictionary(OpenAddressing())
+	})
+
+	for _, implementation := range implementations {
+		opts := implementation.options
+
+		b.Run(implementation.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+
+			for n := 0; n < b.N; n++ {
+				dict := newDecompressDictionary(opts...)
+				var word []byte
+
+				for _, code := range codes {
+					if dict.Size() == lzwDictionarySize {
+						dict = newDecompressDictionary(opts...)
+					}
+
+					entry, ok := dict.Get(code)
+					if !ok {
+						entry = append(word[:len(word):len(word)], word[0])
+					}
+
+					if word != nil {
+						dict.Set(uint16(dict.Size()), append(word[:len(word):len(word)], entry[0]))
+					}
+
+					word = entry
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package dictionary
+
+import (
+	"github.com/mjjs/gompressor/datastructure/linkedlist"
+)
+
+type dictionaryNode[K, V any] struct {
+	key   K
+	value V
+}
+
+// chainingTable is a hash table which stores colliding keys in a linked list
+// per bucket.
+type chainingTable[K, V any] struct {
+	hasher  Hasher[K]
+	buckets []*linkedlist.LinkedList
+	size    int
+}
+
+func newChainingTable[K, V any](hasher Hasher[K], size uint) *chainingTable[K, V] {
+	return &chainingTable[K, V]{
+		hasher:  hasher,
+		buckets: newBuckets(int(size)),
+	}
+}
+
+func (t *chainingTable[K, V]) set(key K, value V) {
+	if node := t.find(key); node != nil {
+		node.value = value
+		return
+	}
+
+	t.getBucket(key).Append(&dictionaryNode[K, V]{key: key, value: value})
+	t.size++
+
+	if float32(t.size)/float32(len(t.buckets)) > maxLoadFactor {
+		t.grow()
+	}
+}
+
+func (t *chainingTable[K, V]) get(key K) (V, bool) {
+	if node := t.find(key); node != nil {
+		return node.value, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+func (t *chainingTable[K, V]) remove(key K) {
+	node := t.find(key)
+	if node == nil {
+		return
+	}
+
+	t.getBucket(key).Remove(node)
+	t.size--
+}
+
+func (t *chainingTable[K, V]) len() int {
+	return t.size
+}
+
+func (t *chainingTable[K, V]) forEach(f func(key K, value V)) {
+	for _, bucket := range t.buckets {
+		bucket.ForEach(func(iNode interface{}) {
+			node := iNode.(*dictionaryNode[K, V])
+			f(node.key, node.value)
+		})
+	}
+}
+
+func (t *chainingTable[K, V]) find(key K) *dictionaryNode[K, V] {
+	var found *dictionaryNode[K, V]
+
+	t.getBucket(key).ForEach(func(iNode interface{}) {
+		if found != nil {
+			return
+		}
+
+		node := iNode.(*dictionaryNode[K, V])
+		if t.hasher.Equal(node.key, key) {
+			found = node
+		}
+	})
+
+	return found
+}
+
+func (t *chainingTable[K, V]) getBucket(key K) *linkedlist.LinkedList {
+	return t.buckets[t.hasher.Hash(key)%uint64(len(t.buckets))]
+}
+
+func (t *chainingTable[K, V]) grow() {
+	buckets := newBuckets(t.size * 2)
+	n := uint64(len(buckets))
+
+	for _, bucket := range t.buckets {
+		bucket.ForEach(func(iNode interface{}) {
+			node := iNode.(*dictionaryNode[K, V])
+			buckets[t.hasher.Hash(node.key)%n].Append(node)
+		})
+	}
+
+	t.buckets = buckets
+}
+
+func newBuckets(n int) []*linkedlist.LinkedList {
+	buckets := make([]*linkedlist.LinkedList, n)
+	for i := range buckets {
+		buckets[i] = new(linkedlist.LinkedList)
+	}
+
+	return buckets
+}
@@ -1,185 +1,110 @@
-// Package dictionary implements a hash table. The hash table accepts strings,
-// uint16 values and bytes as keys, which are the data types used by the
-// compression that require the dictionary.
+// Package dictionary implements a hash table. Keys of any type can be used as
+// long as a Hasher is provided for them. Hashers for integers, strings and
+// byte slices, which are the key types used by the compression algorithms, are
+// provided by the package.
+//
+// Two hash table implementations are available. By default colliding keys are
+// chained into linked lists, and the OpenAddressing option selects a table
+// which stores all entries in a single array using linear probing.
 package dictionary
 
 import (
-	"github.com/mjjs/gompressor/datastructure/linkedlist"
 	"github.com/mjjs/gompressor/datastructure/vector"
 )
 
 const defaultSize uint = 32
 
-type dictionaryNode struct {
-	key   interface{}
-	value interface{}
-}
+const maxLoadFactor float32 = 0.75
 
-// Dictionary implements a hashtable.
-type Dictionary struct {
-	buckets []*linkedlist.LinkedList
-	size    int
+// table is implemented by the hash tables backing a Dictionary.
+type table[K, V any] interface {
+	set(key K, value V)
+	get(key K) (V, bool)
+	remove(key K)
+	len() int
+	forEach(f func(key K, value V))
 }
 
-// New returns a pointer to a new Dictionary with an initial size.
-func New() *Dictionary {
-	return NewWithSize(defaultSize)
+type options struct {
+	openAddressing bool
 }
 
-// NewWithSize returns a pointer to a new Dictionary with the given size. If
-// zero is passed in as size, the default size is used.
-func NewWithSize(size uint) *Dictionary {
-	buckets := make([]*linkedlist.LinkedList, size)
-	for i := range buckets {
-		buckets[i] = new(linkedlist.LinkedList)
-	}
+// Option configures a Dictionary when it is created.
+type Option func(*options)
 
-	return &Dictionary{buckets: buckets}
+// OpenAddressing makes the dictionary use a linear probing hash table instead
+// of chaining colliding keys into linked lists.
+func OpenAddressing() Option {
+	return func(o *options) {
+		o.openAddressing = true
+	}
 }
 
-// Set maps key to value in the dictionary. If key is present in the map, the
-// value is updated.
-func (d *Dictionary) Set(key interface{}, value interface{}) {
-	bucket := d.getBucket(key)
+// Dictionary implements a hashtable which maps keys of type K to values of
+// type V.
+type Dictionary[K, V any] struct {
+	table table[K, V]
+}
 
-	updated := false
+// New returns a pointer to a new Dictionary with an initial size. The hasher
+// is used to hash and compare the keys.
+func New[K, V any](hasher Hasher[K], opts ...Option) *Dictionary[K, V] {
+	return NewWithSize[K, V](hasher, defaultSize, opts...)
+}
 
-	bucket.ForEach(func(iNode interface{}) {
-		if updated {
-			return
-		}
+// NewWithSize returns a pointer to a new Dictionary with the given size. If
+// zero is passed in as size, the default size is used.
+func NewWithSize[K, V any](hasher Hasher[K], size uint, opts ...Option) *Dictionary[K, V] {
+	if size == 0 {
+		size = defaultSize
+	}
 
-		node := iNode.(*dictionaryNode)
-		if node.key == key {
-			node.value = value
-			updated = true
-		}
-	})
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
 
-	if updated {
-		return
+	if o.openAddressing {
+		return &Dictionary[K, V]{table: newOpenAddressingTable[K, V](hasher, size)}
 	}
 
-	bucket.Append(&dictionaryNode{key: key, value: value})
-	d.size++
+	return &Dictionary[K, V]{table: newChainingTable[K, V](hasher, size)}
+}
 
-	if float32(d.size/len(d.buckets)) > 0.75 {
-		d.grow()
-	}
+// Set maps key to value in the dictionary. If key is present in the map, the
+// value is updated.
+func (d *Dictionary[K, V]) Set(key K, value V) {
+	d.table.set(key, value)
 }
 
 // Get returns the value associated with key. An additional boolean value is
 // returned to indicate whether or not the key exists in the map.
-func (d *Dictionary) Get(key interface{}) (interface{}, bool) {
-	bucket := d.getBucket(key)
-
-	var value interface{} = nil
-	found := false
-
-	bucket.ForEach(func(iNode interface{}) {
-		if found {
-			return
-		}
-
-		node := iNode.(*dictionaryNode)
-		if node.key == key {
-			value = node.value
-			found = true
-		}
-	})
-
-	return value, found
+func (d *Dictionary[K, V]) Get(key K) (V, bool) {
+	return d.table.get(key)
 }
 
 // Remove removes the the given key-value pair from the dictionary.
-func (d *Dictionary) Remove(key interface{}) {
-	bucket := d.getBucket(key)
-
-	removed := false
-
-	bucket.ForEach(func(iNode interface{}) {
-		if removed {
-			return
-		}
-
-		node := iNode.(*dictionaryNode)
-		if node.key == key {
-			removed = true
-			bucket.Remove(iNode)
-		}
-	})
-
-	d.size--
+func (d *Dictionary[K, V]) Remove(key K) {
+	d.table.remove(key)
 }
 
 // Size returns the amount of unique values present in the dictionary.
-func (d *Dictionary) Size() int {
-	return d.size
+func (d *Dictionary[K, V]) Size() int {
+	return d.table.len()
 }
 
 // Keys returns a vector containing all the keys in the dictionary.
-func (d *Dictionary) Keys() *vector.Vector {
-	keys := vector.New()
+func (d *Dictionary[K, V]) Keys() *vector.Vector[K] {
+	keys := vector.New[K](0, uint(d.Size()))
 
-	for _, bucket := range d.buckets {
-		bucket.ForEach(func(node interface{}) {
-			keys.Append(node.(*dictionaryNode).key)
-		})
-	}
+	d.ForEach(func(key K, _ V) {
+		keys.Append(key)
+	})
 
 	return keys
 }
 
-func (d *Dictionary) getBucket(key interface{}) *linkedlist.LinkedList {
-	hash := hash(key)
-	n := int64(len(d.buckets))
-
-	return d.buckets[((hash%n)+n)%n]
-}
-
-func (d *Dictionary) grow() {
-	newBuckets := make([]*linkedlist.LinkedList, d.size*2)
-	for i := range newBuckets {
-		newBuckets[i] = new(linkedlist.LinkedList)
-	}
-
-	n := int64(len(newBuckets))
-
-	for _, bucket := range d.buckets {
-		bucket.ForEach(func(node interface{}) {
-			hash := hash((node.(*dictionaryNode).key))
-			newBucket := newBuckets[((hash%n)+n)%n]
-			newBucket.Append(node)
-		})
-	}
-
-	d.buckets = newBuckets
-}
-
-func hash(key interface{}) int64 {
-	switch v := key.(type) {
-	case string:
-		const (
-			prime  int64 = 31
-			modulo int64 = 1e9 + 9
-		)
-
-		var (
-			hash       int64 = 0
-			primePower int64 = 1
-		)
-
-		for _, c := range v {
-			hash = (hash + (int64(c-'a')+1)*primePower) % modulo
-			primePower = (primePower * prime) % modulo
-		}
-
-		return hash
-	case uint16:
-		return int64(v)
-	case byte:
-		return int64(v)
-	default:
-		panic("Unsupported key type")
-	}
+// ForEach executes f for each key-value pair in the dictionary.
+func (d *Dictionary[K, V]) ForEach(f func(key K, value V)) {
+	d.table.forEach(f)
 }
@@ -5,16 +5,18 @@ import (
 )
 
 func TestNewStartsWithInitialSize(t *testing.T) {
-	dictionary := New()
-	if len(dictionary.buckets) != int(defaultSize) {
-		t.Errorf("Expected initial size to be %d, got %d", defaultSize, len(dictionary.buckets))
+	dictionary := New[string, uint16](StringHasher{})
+	buckets := dictionary.table.(*chainingTable[string, uint16]).buckets
+
+	if len(buckets) != int(defaultSize) {
+		t.Errorf("Expected initial size to be %d, got %d", defaultSize, len(buckets))
 	}
 }
 
 func TestNewStartsWithNonNilBuckets(t *testing.T) {
-	dictionary := New()
+	dictionary := New[string, uint16](StringHasher{})
 
-	for _, bucket := range dictionary.buckets {
+	for _, bucket := range dictionary.table.(*chainingTable[string, uint16]).buckets {
 		if bucket == nil {
 			t.Error("Expected buckets to be initialized, got nil bucket")
 		}
@@ -22,7 +24,7 @@ func TestNewStartsWithNonNilBuckets(t *testing.T) {
 }
 
 func TestCanSetAndGet(t *testing.T) {
-	dict := New()
+	dict := New[string, uint16](StringHasher{})
 	key := "key"
 	var expected uint16 = 123
 
@@ -40,7 +42,7 @@ func TestCanSetAndGet(t *testing.T) {
 }
 
 func TestSetUpdatesExistingValue(t *testing.T) {
-	dict := New()
+	dict := New[string, uint16](StringHasher{})
 	key := "key"
 	var expected uint16 = 123
 
@@ -63,7 +65,7 @@ func TestSetUpdatesExistingValue(t *testing.T) {
 }
 
 func TestCanRemoveValues(t *testing.T) {
-	dict := New()
+	dict := New[string, uint16](StringHasher{})
 	key := "key"
 	var expected uint16 = 123
 
@@ -83,7 +85,7 @@ func TestCanRemoveValues(t *testing.T) {
 }
 
 func TestCanGrowPastSize(t *testing.T) {
-	dict := NewWithSize(1)
+	dict := NewWithSize[string, uint16](StringHasher{}, 1)
 	var (
 		key1 string = "key1"
 		key2 string = "key2"
@@ -96,7 +98,7 @@ func TestCanGrowPastSize(t *testing.T) {
 }
 
 func TestElementIsFoundAfterGrow(t *testing.T) {
-	dict := NewWithSize(1)
+	dict := NewWithSize[string, uint16](StringHasher{}, 1)
 	key := "key"
 	val := uint16(15)
 
@@ -112,7 +114,7 @@ func TestElementIsFoundAfterGrow(t *testing.T) {
 }
 
 func TestReturnsSizeCorrectly(t *testing.T) {
-	dict := New()
+	dict := New[string, uint16](StringHasher{})
 	if n := dict.Size(); n != 0 {
 		t.Errorf("Expected size to be %d, got %d", 0, n)
 	}
@@ -124,7 +126,7 @@ func TestReturnsSizeCorrectly(t *testing.T) {
 }
 
 func TestKeys(t *testing.T) {
-	dict := New()
+	dict := New[string, uint16](StringHasher{})
 	dict.Set("a", 1)
 	dict.Set("b", 2)
 	dict.Set("c", 3)
@@ -147,3 +149,72 @@ func TestKeys(t *testing.T) {
 		t.Errorf("Expected key to be %s, got %v", "c", key)
 	}
 }
+
+func TestRemoveKeepsSizeForMissingKey(t *testing.T) {
+	dict := New[string, uint16](StringHasher{})
+	dict.Set("a", 1)
+	dict.Remove("b")
+
+	if n := dict.Size(); n != 1 {
+		t.Errorf("Expected size to be %d, got %d", 1, n)
+	}
+}
+
+func TestForEachVisitsAllPairs(t *testing.T) {
+	dict := New[uint16, string](IntegerHasher[uint16]{})
+	expected := map[uint16]string{1: "a", 2: "b", 300: "c"}
+
+	for key, value := range expected {
+		dict.Set(key, value)
+	}
+
+	visited := 0
+	dict.ForEach(func(key uint16, value string) {
+		visited++
+
+		if expected[key] != value {
+			t.Errorf("Expected %d to map to %s, got %s", key, expected[key], value)
+		}
+	})
+
+	if visited != len(expected) {
+		t.Errorf("Expected %d pairs to be visited, got %d", len(expected), visited)
+	}
+}
+
+func TestBytesHasherComparesContents(t *testing.T) {
+	dict := NewWithSize[[]byte, int](BytesHasher{}, 1)
+	dict.Set([]byte("abc"), 1)
+	dict.Set([]byte("abd"), 2)
+	dict.Set([]byte("abc"), 3)
+
+	if n := dict.Size(); n != 2 {
+		t.Errorf("Expected size to be %d, got %d", 2, n)
+	}
+
+	if v, ok := dict.Get([]byte("abc")); !ok {
+		t.Errorf("Expected %t got %t", true, ok)
+	} else if v != 3 {
+		t.Errorf("Expected %d got %d", 3, v)
+	}
+}
+
+func TestHashersReturnEqualHashesForEqualKeys(t *testing.T) {
+	var (
+		stringHasher  StringHasher
+		bytesHasher   BytesHasher
+		integerHasher IntegerHasher[byte]
+	)
+
+	if stringHasher.Hash("hello") != stringHasher.Hash("hello") {
+		t.Error("Expected equal strings to have equal hashes")
+	}
+
+	if bytesHasher.Hash([]byte("hello")) != stringHasher.Hash("hello") {
+		t.Error("Expected byte slices to hash like the equivalent string")
+	}
+
+	if h := integerHasher.Hash(42); h != 42 {
+		t.Errorf("Expected %d, got %d", 42, h)
+	}
+}
@@ -0,0 +1,85 @@
+package dictionary
+
+// Hasher computes hashes for keys of type K and tells whether two keys are
+// equal. Keys that are equal must have the same hash.
+type Hasher[K any] interface {
+	Hash(key K) uint64
+	Equal(a, b K) bool
+}
+
+// Integer is a constraint that permits any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// IntegerHasher is a Hasher for integer keys. The hash of a key is the key
+// itself.
+type IntegerHasher[K Integer] struct{}
+
+// Hash returns the hash of key.
+func (IntegerHasher[K]) Hash(key K) uint64 {
+	return uint64(key)
+}
+
+// Equal reports whether a and b are equal.
+func (IntegerHasher[K]) Equal(a, b K) bool {
+	return a == b
+}
+
+// StringHasher is a Hasher for string keys.
+type StringHasher struct{}
+
+// Hash returns the hash of key.
+func (StringHasher) Hash(key string) uint64 {
+	h := newPolynomialHash()
+	for i := 0; i < len(key); i++ {
+		h.add(key[i])
+	}
+
+	return h.sum
+}
+
+// Equal reports whether a and b are equal.
+func (StringHasher) Equal(a, b string) bool {
+	return a == b
+}
+
+// BytesHasher is a Hasher for byte slice keys. Slices with the same contents
+// are considered equal.
+type BytesHasher struct{}
+
+// Hash returns the hash of key.
+func (BytesHasher) Hash(key []byte) uint64 {
+	h := newPolynomialHash()
+	for _, b := range key {
+		h.add(b)
+	}
+
+	return h.sum
+}
+
+// Equal reports whether a and b have the same contents.
+func (BytesHasher) Equal(a, b []byte) bool {
+	return string(a) == string(b)
+}
+
+// polynomialHash implements a polynomial rolling hash over a sequence of bytes.
+type polynomialHash struct {
+	sum        uint64
+	primePower uint64
+}
+
+const (
+	hashPrime  uint64 = 31
+	hashModulo uint64 = 1e9 + 9
+)
+
+func newPolynomialHash() polynomialHash {
+	return polynomialHash{primePower: 1}
+}
+
+func (h *polynomialHash) add(b byte) {
+	h.sum = (h.sum + (uint64(b)+1)*h.primePower) % hashModulo
+	h.primePower = (h.primePower * hashPrime) % hashModulo
+}
@@ -0,0 +1,150 @@
+package dictionary
+
+import (
+	"math/bits"
+)
+
+// minOpenAddressingSize is the smallest number of slots an open addressing
+// table is created with.
+const minOpenAddressingSize uint = 8
+
+// fibonacciMultiplier is 2^64 divided by the golden ratio. Multiplying a hash
+// with it spreads the entropy of the hash into the high bits, which are used
+// as the slot index.
+const fibonacciMultiplier uint64 = 11400714819323198485
+
+type slot[K, V any] struct {
+	hash  uint64
+	key   K
+	value V
+	used  bool
+}
+
+// openAddressingTable is a hash table which stores all the entries in a
+// single slice. Collisions are resolved with linear probing, and removals
+// shift the following entries back so that no tombstones are needed.
+type openAddressingTable[K, V any] struct {
+	hasher Hasher[K]
+	slots  []slot[K, V]
+	shift  uint
+	size   int
+}
+
+func newOpenAddressingTable[K, V any](hasher Hasher[K], size uint) *openAddressingTable[K, V] {
+	if size < minOpenAddressingSize {
+		size = minOpenAddressingSize
+	}
+
+	// Round the size up to the next power of two so that the slot index can
+	// be taken from the top bits of the hash.
+	log2 := uint(bits.Len(size - 1))
+
+	return &openAddressingTable[K, V]{
+		hasher: hasher,
+		slots:  make([]slot[K, V], 1<<log2),
+		shift:  64 - log2,
+	}
+}
+
+func (t *openAddressingTable[K, V]) set(key K, value V) {
+	hash := t.hasher.Hash(key)
+
+	if i, found := t.find(key, hash); found {
+		t.slots[i].value = value
+		return
+	}
+
+	if float32(t.size+1)/float32(len(t.slots)) > maxLoadFactor {
+		t.grow()
+	}
+
+	t.insert(hash, key, value)
+	t.size++
+}
+
+func (t *openAddressingTable[K, V]) get(key K) (V, bool) {
+	if i, found := t.find(key, t.hasher.Hash(key)); found {
+		return t.slots[i].value, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+func (t *openAddressingTable[K, V]) remove(key K) {
+	i, found := t.find(key, t.hasher.Hash(key))
+	if !found {
+		return
+	}
+
+	mask := len(t.slots) - 1
+
+	// Shift back every entry in the probe sequence following the removed
+	// entry whose home slot is not between the hole and the entry itself.
+	for j := (i + 1) & mask; t.slots[j].used; j = (j + 1) & mask {
+		home := t.home(t.slots[j].hash)
+
+		if i <= j && (i < home && home <= j) || i > j && (i < home || home <= j) {
+			continue
+		}
+
+		t.slots[i] = t.slots[j]
+		i = j
+	}
+
+	t.slots[i] = slot[K, V]{}
+	t.size--
+}
+
+func (t *openAddressingTable[K, V]) len() int {
+	return t.size
+}
+
+func (t *openAddressingTable[K, V]) forEach(f func(key K, value V)) {
+	for i := range t.slots {
+		if t.slots[i].used {
+			f(t.slots[i].key, t.slots[i].value)
+		}
+	}
+}
+
+// find returns the index of the slot holding key. If the key is not in the
+// table, the index of the empty slot ending the probe sequence is returned
+// with false.
+func (t *openAddressingTable[K, V]) find(key K, hash uint64) (int, bool) {
+	mask := len(t.slots) - 1
+
+	for i := t.home(hash); ; i = (i + 1) & mask {
+		s := &t.slots[i]
+
+		if !s.used {
+			return i, false
+		}
+
+		if s.hash == hash && t.hasher.Equal(s.key, key) {
+			return i, true
+		}
+	}
+}
+
+func (t *openAddressingTable[K, V]) insert(hash uint64, key K, value V) {
+	i, _ := t.find(key, hash)
+	t.slots[i] = slot[K, V]{hash: hash, key: key, value: value, used: true}
+}
+
+func (t *openAddressingTable[K, V]) home(hash uint64) int {
+	return int((hash * fibonacciMultiplier) >> t.shift)
+}
+
+func (t *openAddressingTable[K, V]) grow() {
+	old := t.slots
+
+	t.slots = make([]slot[K, V], len(old)*2)
+	t.shift--
+
+	for i := range old {
+		if old[i].used {
+			t.insert(old[i].hash, old[i].key, old[i].value)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package dictionary
+
+import (
+	"testing"
+)
+
+// collidingHasher hashes every key into the same slot to exercise probing.
+type collidingHasher struct{}
+
+func (collidingHasher) Hash(key int) uint64 {
+	return 7
+}
+
+func (collidingHasher) Equal(a, b int) bool {
+	return a == b
+}
+
+func TestOpenAddressingUsesOpenAddressingTable(t *testing.T) {
+	dict := New[string, uint16](StringHasher{}, OpenAddressing())
+
+	if _, ok := dict.table.(*openAddressingTable[string, uint16]); !ok {
+		t.Errorf("Expected an open addressing table, got %T", dict.table)
+	}
+}
+
+func TestOpenAddressingSizeIsRoundedToPowerOfTwo(t *testing.T) {
+	dict := NewWithSize[string, uint16](StringHasher{}, 100, OpenAddressing())
+	slots := dict.table.(*openAddressingTable[string, uint16]).slots
+
+	if len(slots) != 128 {
+		t.Errorf("Expected %d slots, got %d", 128, len(slots))
+	}
+}
+
+func TestOpenAddressingCanSetGetAndUpdate(t *testing.T) {
+	dict := New[string, uint16](StringHasher{}, OpenAddressing())
+
+	if _, exists := dict.Get("key"); exists {
+		t.Errorf("Expected %t, got %t", false, exists)
+	}
+
+	dict.Set("key", 123)
+	dict.Set("key", 212)
+
+	if actual, exists := dict.Get("key"); !exists {
+		t.Errorf("Expected %t, got %t", true, exists)
+	} else if actual != 212 {
+		t.Errorf("Expected %v, got %v", 212, actual)
+	}
+
+	if n := dict.Size(); n != 1 {
+		t.Errorf("Expected size to be %d, got %d", 1, n)
+	}
+}
+
+func TestOpenAddressingElementsAreFoundAfterGrow(t *testing.T) {
+	dict := NewWithSize[uint16, int](IntegerHasher[uint16]{}, 1, OpenAddressing())
+
+	for i := 0; i < 1000; i++ {
+		dict.Set(uint16(i), i*2)
+	}
+
+	if n := dict.Size(); n != 1000 {
+		t.Errorf("Expected size to be %d, got %d", 1000, n)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if v, ok := dict.Get(uint16(i)); !ok {
+			t.Errorf("Expected %d to be found", i)
+		} else if v != i*2 {
+			t.Errorf("Expected %d got %d", i*2, v)
+		}
+	}
+}
+
+func TestOpenAddressingRemoveKeepsCollidingKeysReachable(t *testing.T) {
+	dict := New[int, int](collidingHasher{}, OpenAddressing())
+
+	for i := 0; i < 5; i++ {
+		dict.Set(i, i)
+	}
+
+	dict.Remove(1)
+	dict.Remove(42)
+
+	if n := dict.Size(); n != 4 {
+		t.Errorf("Expected size to be %d, got %d", 4, n)
+	}
+
+	if _, ok := dict.Get(1); ok {
+		t.Error("Expected removed key not to be found")
+	}
+
+	for _, key := range []int{0, 2, 3, 4} {
+		if v, ok := dict.Get(key); !ok {
+			t.Errorf("Expected %d to be found after removal", key)
+		} else if v != key {
+			t.Errorf("Expected %d got %d", key, v)
+		}
+	}
+}
+
+func TestOpenAddressingRemoveAcrossWrapAround(t *testing.T) {
+	dict := NewWithSize[int, int](IntegerHasher[int]{}, 8, OpenAddressing())
+	table := dict.table.(*openAddressingTable[int, int])
+
+	// Find keys whose home slot is the last slot so that probing wraps around
+	// to the beginning of the table.
+	var keys []int
+	for key := 0; len(keys) < 3; key++ {
+		if table.home(IntegerHasher[int]{}.Hash(key)) == len(table.slots)-1 {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		dict.Set(key, key)
+	}
+
+	dict.Remove(keys[0])
+
+	for _, key := range keys[1:] {
+		if _, ok := dict.Get(key); !ok {
+			t.Errorf("Expected %d to be found after removal", key)
+		}
+	}
+}
+
+func TestOpenAddressingForEachVisitsAllPairs(t *testing.T) {
+	dict := New[string, int](StringHasher{}, OpenAddressing())
+	expected := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	for key, value := range expected {
+		dict.Set(key, value)
+	}
+
+	keys := dict.Keys()
+	if keys.Size() != len(expected) {
+		t.Errorf("Expected %d keys, got %d", len(expected), keys.Size())
+	}
+
+	dict.ForEach(func(key string, value int) {
+		if expected[key] != value {
+			t.Errorf("Expected %s to map to %d, got %d", key, expected[key], value)
+		}
+	})
+}
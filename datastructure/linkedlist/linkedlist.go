@@ -41,8 +41,18 @@ func (ll *LinkedList) Append(value interface{}) {
 
 // Remove removes value from the linked list.
 func (ll *LinkedList) Remove(value interface{}) {
+	if ll.head == nil {
+		return
+	}
+
 	if ll.head.value == value {
 		ll.head = ll.head.next
+		if ll.head == nil {
+			ll.tail = nil
+		}
+
+		ll.size--
+		return
 	}
 
 	removed := ll.remove(ll.head, value)
@@ -61,6 +61,27 @@ func TestRemoveCanRemoveHead(t *testing.T) {
 	}
 }
 
+func TestRemoveLastValueEmptiesList(t *testing.T) {
+	ll := &LinkedList{}
+
+	ll.Append(1)
+	ll.Remove(1)
+
+	if ll.Size() != 0 {
+		t.Errorf("Expected size to be 0, got %d", ll.Size())
+	}
+
+	if ll.Head() != nil || ll.Tail() != nil {
+		t.Errorf("Expected head and tail to be nil, got %v and %v", ll.Head(), ll.Tail())
+	}
+
+	ll.Append(2)
+
+	if ll.Head() != 2 {
+		t.Errorf("Expected %v to become head, got %v", 2, ll.Head())
+	}
+}
+
 func TestRemoveCanRemoveTail(t *testing.T) {
 	ll := &LinkedList{}
 
@@ -6,31 +6,34 @@ import (
 	"github.com/mjjs/gompressor/datastructure/vector"
 )
 
-type node struct {
+type node[T any] struct {
 	priority int
-	value    interface{}
+	value    T
 }
 
-// PriorityQueue is the type which implements the priority queue.
-type PriorityQueue struct {
-	nodes vector.Vector
+// PriorityQueue is the type which implements the priority queue. The zero
+// value is an empty queue ready to use.
+type PriorityQueue[T any] struct {
+	nodes vector.Vector[*node[T]]
 }
 
 // Enqueue adds value to the queue with the given priority. Elements are re-ordered
 // if needed so that the heap property is satisfied.
-func (pq *PriorityQueue) Enqueue(priority int, value interface{}) {
-	pq.nodes.Append(&node{priority: priority, value: value})
+func (pq *PriorityQueue[T]) Enqueue(priority int, value T) {
+	pq.nodes.Append(&node[T]{priority: priority, value: value})
 	pq.siftUp(pq.nodes.Size() - 1)
 }
 
 // Dequeue removes the element with the highest priority and returns it to the caller.
-// The rest of the tree is re-ordered to satisfy the heap property.
-func (pq *PriorityQueue) Dequeue() (int, interface{}) {
+// The rest of the tree is re-ordered to satisfy the heap property. The zero
+// value of T is returned if the queue is empty.
+func (pq *PriorityQueue[T]) Dequeue() (int, T) {
 	if pq.nodes.Size() == 0 {
-		return 0, nil
+		var zero T
+		return 0, zero
 	}
 
-	head := pq.nodes.MustGet(0).(*node)
+	head := pq.nodes.MustGet(0)
 
 	pq.nodes.MustSet(0, pq.nodes.MustGet(pq.nodes.Size()-1))
 	pq.nodes.Pop()
@@ -43,27 +46,28 @@ func (pq *PriorityQueue) Dequeue() (int, interface{}) {
 }
 
 // Peek returns the highest priority element to the caller without removing it
-// from the queue.
-func (pq *PriorityQueue) Peek() (int, interface{}) {
+// from the queue. The zero value of T is returned if the queue is empty.
+func (pq *PriorityQueue[T]) Peek() (int, T) {
 	if pq.nodes.Size() == 0 {
-		return 0, nil
+		var zero T
+		return 0, zero
 	}
 
-	node := pq.nodes.MustGet(0).(*node)
+	node := pq.nodes.MustGet(0)
 
 	return node.priority, node.value
 }
 
 // Size returns the amount of elements in the queue.
-func (pq *PriorityQueue) Size() int {
+func (pq *PriorityQueue[T]) Size() int {
 	return pq.nodes.Size()
 }
 
-func (pq *PriorityQueue) siftUp(i int) {
+func (pq *PriorityQueue[T]) siftUp(i int) {
 	parent := (i - 1) / 2
 
-	currentNode := pq.nodes.MustGet(i).(*node)
-	parentNode := pq.nodes.MustGet(parent).(*node)
+	currentNode := pq.nodes.MustGet(i)
+	parentNode := pq.nodes.MustGet(parent)
 
 	if currentNode.priority < parentNode.priority {
 		pq.swap(i, parent)
@@ -71,7 +75,7 @@ func (pq *PriorityQueue) siftUp(i int) {
 	}
 }
 
-func (pq *PriorityQueue) siftDown(i int) {
+func (pq *PriorityQueue[T]) siftDown(i int) {
 	if i >= pq.nodes.Size()/2 && i <= pq.nodes.Size() {
 		return
 	}
@@ -80,11 +84,11 @@ func (pq *PriorityQueue) siftDown(i int) {
 	left := i*2 + 1
 	right := i*2 + 2
 
-	if left < pq.nodes.Size() && pq.nodes.MustGet(left).(*node).priority < pq.nodes.MustGet(smallest).(*node).priority {
+	if left < pq.nodes.Size() && pq.nodes.MustGet(left).priority < pq.nodes.MustGet(smallest).priority {
 		smallest = left
 	}
 
-	if right < pq.nodes.Size() && pq.nodes.MustGet(right).(*node).priority < pq.nodes.MustGet(smallest).(*node).priority {
+	if right < pq.nodes.Size() && pq.nodes.MustGet(right).priority < pq.nodes.MustGet(smallest).priority {
 		smallest = right
 	}
 
@@ -94,7 +98,7 @@ func (pq *PriorityQueue) siftDown(i int) {
 	}
 }
 
-func (pq *PriorityQueue) swap(i, j int) {
+func (pq *PriorityQueue[T]) swap(i, j int) {
 	temp := pq.nodes.MustGet(i)
 	pq.nodes.MustSet(i, pq.nodes.MustGet(j))
 	pq.nodes.MustSet(j, temp)
@@ -5,7 +5,7 @@ import (
 )
 
 func TestDequeueReturnsSmallestPriorityFirst(t *testing.T) {
-	pq := &PriorityQueue{}
+	pq := &PriorityQueue[int]{}
 	pq.Enqueue(1, 1)
 	pq.Enqueue(2, 2)
 	pq.Enqueue(1, 3)
@@ -29,7 +29,7 @@ func TestDequeueReturnsSmallestPriorityFirst(t *testing.T) {
 }
 
 func TestDequeueReturnsFirstQueuedValueForEqualPriorities(t *testing.T) {
-	pq := &PriorityQueue{}
+	pq := &PriorityQueue[int]{}
 	pq.Enqueue(1, 1)
 	pq.Enqueue(1, 2)
 	pq.Enqueue(2, 4)
@@ -53,7 +53,7 @@ func TestDequeueReturnsFirstQueuedValueForEqualPriorities(t *testing.T) {
 }
 
 func TestPeekReturnsSmallestPriorityWithoutRemovingIt(t *testing.T) {
-	pq := &PriorityQueue{}
+	pq := &PriorityQueue[string]{}
 	pq.Enqueue(9, "g")
 	pq.Enqueue(5, "a")
 	pq.Enqueue(8, "b")
@@ -67,22 +67,22 @@ func TestPeekReturnsSmallestPriorityWithoutRemovingIt(t *testing.T) {
 	}
 }
 
-func TestPeekReturnsNilOnEmptyQueue(t *testing.T) {
-	pq := &PriorityQueue{}
-	if _, val := pq.Peek(); val != nil {
-		t.Errorf("Expected nil, got %v", val)
+func TestPeekReturnsZeroValueOnEmptyQueue(t *testing.T) {
+	pq := &PriorityQueue[string]{}
+	if _, val := pq.Peek(); val != "" {
+		t.Errorf("Expected zero value, got %q", val)
 	}
 }
 
-func TestDequeueReturnsNilOnEmptyQueue(t *testing.T) {
-	pq := &PriorityQueue{}
-	if _, val := pq.Dequeue(); val != nil {
-		t.Errorf("Expected nil, got %v", val)
+func TestDequeueReturnsZeroValueOnEmptyQueue(t *testing.T) {
+	pq := &PriorityQueue[string]{}
+	if _, val := pq.Dequeue(); val != "" {
+		t.Errorf("Expected zero value, got %q", val)
 	}
 }
 
 func TestDequeueRemovesNodes(t *testing.T) {
-	pq := &PriorityQueue{}
+	pq := &PriorityQueue[string]{}
 	pq.Enqueue(3, "a")
 
 	if pq.nodes.Size() != 1 {
@@ -97,7 +97,7 @@ func TestDequeueRemovesNodes(t *testing.T) {
 }
 
 func TestSize(t *testing.T) {
-	pq := &PriorityQueue{}
+	pq := &PriorityQueue[int]{}
 
 	if n := pq.Size(); n != 0 {
 		t.Errorf("Expected size to be 0, got %d", n)
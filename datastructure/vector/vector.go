@@ -13,26 +13,26 @@ const defaultCapacity int = 10
 // vector.
 var ErrIndexOutOfRange = errors.New("index out of range")
 
-// Vector is a dynamic array that holds bytes.
-type Vector struct {
+// Vector is a dynamic array that holds elements of type T.
+type Vector[T any] struct {
 	size     int
 	capacity int
-	elements []interface{}
+	elements []T
 }
 
 // New returns a pointer to a Vector. The size specifies the length of the
-// initial vector. The vector will hold size amount of zero valued bytes.
+// initial vector. The vector will hold size amount of zero valued elements.
 //
 // An additional uint can be passed to New, which indicates how many values
 // the underlying array can hold before resizing. If capacity is smaller than length,
 // capacity will be set to equal length.
-func New(size ...uint) *Vector {
+func New[T any](size ...uint) *Vector[T] {
 	switch len(size) {
 	case 1:
-		return &Vector{
+		return &Vector[T]{
 			size:     int(size[0]),
 			capacity: int(size[0]),
-			elements: make([]interface{}, size[0]),
+			elements: make([]T, size[0]),
 		}
 
 	case 2:
@@ -43,21 +43,32 @@ func New(size ...uint) *Vector {
 			capacity = sz
 		}
 
-		return &Vector{
+		return &Vector[T]{
 			capacity: capacity,
 			size:     sz,
-			elements: make([]interface{}, capacity),
+			elements: make([]T, capacity),
 		}
 
 	default:
-		return &Vector{
-			elements: make([]interface{}, 0),
+		return &Vector[T]{
+			elements: make([]T, 0),
 		}
 	}
 }
 
+// FromSlice returns a vector holding the elements of the slice without copying
+// them. The vector shares its storage with the slice until it grows, so a
+// vector over read-only memory must not be modified.
+func FromSlice[T any](elements []T) *Vector[T] {
+	return &Vector[T]{
+		size:     len(elements),
+		capacity: len(elements),
+		elements: elements,
+	}
+}
+
 // Append adds the values to the end of the vector, growing it if necessary.
-func (v *Vector) Append(values ...interface{}) {
+func (v *Vector[T]) Append(values ...T) {
 	for _, value := range values {
 		if v.size == v.capacity {
 			v.grow()
@@ -68,10 +79,12 @@ func (v *Vector) Append(values ...interface{}) {
 	}
 }
 
-// Pop removes the last element from the vector.
-func (v *Vector) Pop() interface{} {
+// Pop removes the last element from the vector and returns it. The zero value
+// of T is returned if the vector is empty.
+func (v *Vector[T]) Pop() T {
 	if v.size == 0 {
-		return nil
+		var zero T
+		return zero
 	}
 
 	tail := v.elements[v.size-1]
@@ -81,12 +94,10 @@ func (v *Vector) Pop() interface{} {
 
 // AppendToCopy creates a copy of v and appends the values to the end of the
 // copy and returns the copy. The copy is grown if necessary.
-func (v *Vector) AppendToCopy(values ...interface{}) *Vector {
-	newVector := New(uint(v.size), uint(v.capacity))
+func (v *Vector[T]) AppendToCopy(values ...T) *Vector[T] {
+	newVector := New[T](uint(v.size), uint(v.capacity))
 
-	for i := 0; i < v.size; i++ {
-		newVector.Set(i, v.MustGet(i))
-	}
+	copy(newVector.elements, v.elements[:v.size])
 
 	newVector.Append(values...)
 
@@ -95,21 +106,22 @@ func (v *Vector) AppendToCopy(values ...interface{}) *Vector {
 
 // Get returns the value at the given index. Returns an error if the index
 // is out or range.
-func (v *Vector) Get(index int) (interface{}, error) {
+func (v *Vector[T]) Get(index int) (T, error) {
 	if index < 0 || index >= v.size {
-		return nil, fmt.Errorf("%w: %d", ErrIndexOutOfRange, index)
+		var zero T
+		return zero, fmt.Errorf("%w: %d", ErrIndexOutOfRange, index)
 	}
 
 	return v.elements[index], nil
 }
 
 // MustGet returns the value at the given index without performing any bounds checking.
-func (v *Vector) MustGet(index int) interface{} {
+func (v *Vector[T]) MustGet(index int) T {
 	return v.elements[index]
 }
 
 // Set sets value into the given index. Returns an error if the index is out of range.
-func (v *Vector) Set(index int, value interface{}) error {
+func (v *Vector[T]) Set(index int, value T) error {
 	if index < 0 || index >= v.size {
 		return fmt.Errorf("%w: %d", ErrIndexOutOfRange, index)
 	}
@@ -120,48 +132,48 @@ func (v *Vector) Set(index int, value interface{}) error {
 }
 
 // MustSet sets value into the given index without performing any bounds checking.
-func (v *Vector) MustSet(index int, value interface{}) {
+func (v *Vector[T]) MustSet(index int, value T) {
 	v.elements[index] = value
 }
 
+// Slice returns the elements of the vector as a slice. The slice shares its
+// storage with the vector, so changes to one are visible in the other until the
+// vector grows.
+func (v *Vector[T]) Slice() []T {
+	return v.elements[:v.size]
+}
+
 // Size returns the number of elements in the vector.
-func (v *Vector) Size() int {
+func (v *Vector[T]) Size() int {
 	return v.size
 }
 
 // Capacity returns the number of elements the vector can hold before resizing.
-func (v *Vector) Capacity() int {
+func (v *Vector[T]) Capacity() int {
 	return v.capacity
 }
 
-// String returns the string representation of the bytes in the vector.
-func (v *Vector) String() string {
-	s := ""
-
-	for i := 0; i < v.size; i++ {
-		switch val := v.elements[i].(type) {
-		case byte:
-			s += string([]byte{val})
-		default:
-			panic(fmt.Sprintf("String not implemented for %T", v.elements[i]))
-		}
+// String returns the string representation of the bytes in the vector. String
+// panics if the vector holds anything other than bytes.
+func (v *Vector[T]) String() string {
+	bytes, ok := any(v.elements[:v.size]).([]byte)
+	if !ok {
+		var zero T
+		panic(fmt.Sprintf("String not implemented for %T", zero))
 	}
 
-	return s
+	return string(bytes)
 }
 
-func (v *Vector) grow() {
+func (v *Vector[T]) grow() {
 	newCapacity := v.capacity * 2
 
 	if v.capacity == 0 {
 		newCapacity = 1
 	}
 
-	newElements := make([]interface{}, newCapacity)
-
-	for i := 0; i < v.size; i++ {
-		newElements[i] = v.elements[i]
-	}
+	newElements := make([]T, newCapacity)
+	copy(newElements, v.elements[:v.size])
 
 	v.capacity = newCapacity
 	v.elements = newElements
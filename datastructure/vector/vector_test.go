@@ -19,7 +19,7 @@ var newTestCases = []struct {
 func TestNew(t *testing.T) {
 	for _, testCase := range newTestCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			bv := New(testCase.input...)
+			bv := New[byte](testCase.input...)
 			if actualCapacity := bv.Capacity(); actualCapacity != testCase.expectedCapacity {
 				t.Errorf("Expected capacity to be %d, got %d", testCase.expectedCapacity, actualCapacity)
 			}
@@ -31,8 +31,29 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestFromSliceSharesStorage(t *testing.T) {
+	elements := []byte{1, 2, 3}
+	bv := FromSlice(elements)
+
+	if bv.Size() != 3 || bv.Capacity() != 3 {
+		t.Errorf("Expected size and capacity to be %d, got %d and %d", 3, bv.Size(), bv.Capacity())
+	}
+
+	elements[0] = 42
+	if actual := bv.MustGet(0); actual != 42 {
+		t.Errorf("Expected %d, got %d", 42, actual)
+	}
+
+	bv.Append(4)
+	elements[1] = 42
+
+	if actual := bv.MustGet(1); actual != 2 {
+		t.Errorf("Expected %d, got %d", 2, actual)
+	}
+}
+
 func TestAppendAddsValuesToEnd(t *testing.T) {
-	bv := New()
+	bv := New[byte]()
 
 	for i := 0; i < 5; i++ {
 		bv.Append(byte(i + 1))
@@ -49,7 +70,7 @@ func TestAppendAddsValuesToEnd(t *testing.T) {
 }
 
 func TestCanAppendPastGivenSize(t *testing.T) {
-	bv := New()
+	bv := New[byte]()
 
 	for i := 0; i < 20; i++ {
 		bv.Append(byte(i))
@@ -63,7 +84,7 @@ func TestCanAppendPastGivenSize(t *testing.T) {
 }
 
 func TestAppendToCopyCreatesNewCopy(t *testing.T) {
-	bv := New()
+	bv := New[byte]()
 	bv.Append(byte(1), byte(2), byte(3))
 	newBV := bv.AppendToCopy(byte(123))
 
@@ -78,13 +99,13 @@ func TestAppendToCopyCreatesNewCopy(t *testing.T) {
 
 var setAndGetTestCases = []struct {
 	name        string
-	vector      *Vector
+	vector      *Vector[byte]
 	index       int
 	shouldError bool
 }{
-	{name: "Sets valid index without error", vector: New(5, 5), index: 2, shouldError: false},
-	{name: "Panics on negative index", vector: New(5, 5), index: -1, shouldError: true},
-	{name: "Panics on too large index", vector: New(5, 5), index: 50, shouldError: true},
+	{name: "Sets valid index without error", vector: New[byte](5, 5), index: 2, shouldError: false},
+	{name: "Panics on negative index", vector: New[byte](5, 5), index: -1, shouldError: true},
+	{name: "Panics on too large index", vector: New[byte](5, 5), index: 50, shouldError: true},
 }
 
 func TestSetAndGet(t *testing.T) {
@@ -114,14 +135,14 @@ func TestSetAndGet(t *testing.T) {
 
 var mustSetAndGetTestCases = []struct {
 	name        string
-	vector      *Vector
+	vector      *Vector[byte]
 	index       int
 	input       byte
 	shouldPanic bool
 }{
-	{name: "Sets valid index without panic", vector: New(5, 5), index: 2, shouldPanic: false},
-	{name: "Panics on negative index", vector: New(5, 5), index: -1, shouldPanic: true},
-	{name: "Panics on too large index", vector: New(5, 5), index: 50, shouldPanic: true},
+	{name: "Sets valid index without panic", vector: New[byte](5, 5), index: 2, shouldPanic: false},
+	{name: "Panics on negative index", vector: New[byte](5, 5), index: -1, shouldPanic: true},
+	{name: "Panics on too large index", vector: New[byte](5, 5), index: 50, shouldPanic: true},
 }
 
 func TestMustSetAndGet(t *testing.T) {
@@ -161,7 +182,7 @@ func TestMustGet(t *testing.T) {
 }
 
 func TestString(t *testing.T) {
-	bv := New()
+	bv := New[byte]()
 	input := "Hello World"
 
 	for _, c := range input {
@@ -180,14 +201,14 @@ func TestStringPanicsOnInvalidType(t *testing.T) {
 		}
 	}()
 
-	vec := New()
+	vec := New[int]()
 	vec.Append(1, 2, 3)
 
 	_ = vec.String()
 }
 
 func TestPop(t *testing.T) {
-	vec := New()
+	vec := New[int]()
 	vec.Append(1, 2, 3, 4)
 
 	for i := 4; i > 0; i-- {
@@ -200,7 +221,23 @@ func TestPop(t *testing.T) {
 		}
 	}
 
-	if val := vec.Pop(); val != nil {
-		t.Errorf("Expected nil to be returned from popping empty vector, got %v", val)
+	if val := vec.Pop(); val != 0 {
+		t.Errorf("Expected zero value to be returned from popping empty vector, got %v", val)
+	}
+}
+
+func TestSliceSharesElements(t *testing.T) {
+	vec := New[byte](0, 10)
+	vec.Append(1, 2, 3)
+
+	slice := vec.Slice()
+	if len(slice) != 3 {
+		t.Errorf("Expected length to be %d, got %d", 3, len(slice))
+	}
+
+	slice[1] = 5
+
+	if val := vec.MustGet(1); val != 5 {
+		t.Errorf("Expected %d, got %d", 5, val)
 	}
 }
@@ -0,0 +1,115 @@
+package fileio
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// newFileMode is the mode of files created by CreateAtomic which do not
+// replace an existing file.
+const newFileMode fs.FileMode = 0644
+
+// AtomicFile is a temporary file which replaces its destination file only when
+// it is committed. A crash or an error while writing leaves the destination
+// file untouched, instead of leaving it partially written.
+type AtomicFile struct {
+	*os.File
+	filename string
+	closed   bool
+}
+
+// CreateAtomic creates a temporary file next to the destination file. The
+// temporary file gets the mode of the destination file if it exists.
+func CreateAtomic(filename string) (*AtomicFile, error) {
+	absolutePath, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := newFileMode
+	if info, err := os.Stat(absolutePath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	dir, base := filepath.Split(absolutePath)
+
+	file, err := os.CreateTemp(dir, "."+base+".tmp*")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := file.Chmod(mode); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+
+		return nil, err
+	}
+
+	return &AtomicFile{File: file, filename: absolutePath}, nil
+}
+
+// Commit flushes the written data to disk and renames the temporary file over
+// the destination file.
+func (f *AtomicFile) Commit() error {
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	f.closed = true
+
+	err := f.File.Sync()
+	if closeErr := f.File.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(f.File.Name(), f.filename)
+	}
+
+	if err != nil {
+		os.Remove(f.File.Name())
+		return err
+	}
+
+	return syncDir(filepath.Dir(f.filename))
+}
+
+// Close removes the temporary file if it has not been committed. Close can be
+// called after Commit.
+func (f *AtomicFile) Close() error {
+	if f.closed {
+		return nil
+	}
+
+	f.closed = true
+
+	err := f.File.Close()
+	if removeErr := os.Remove(f.File.Name()); err == nil {
+		err = removeErr
+	}
+
+	return err
+}
+
+// syncDir flushes the directory entries of the directory to disk, so that a
+// rename survives a crash.
+func syncDir(dir string) error {
+	// Directories can not be synced on Windows.
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	err = file.Sync()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
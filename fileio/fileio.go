@@ -2,23 +2,18 @@ package fileio
 
 import (
 	"bufio"
-	"encoding/binary"
-	"errors"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
+	"github.com/mjjs/gompressor/algorithm/lzw"
 	"github.com/mjjs/gompressor/datastructure/vector"
 )
 
-func WriteLZWFile(codes *vector.Vector, filename string) error {
-	absolutePath, err := filepath.Abs(filename)
-	if err != nil {
-		return err
-	}
-
-	file, err := os.Create(absolutePath)
+// WriteLZWFile writes the LZW codes into the file atomically.
+func WriteLZWFile(codes *vector.Vector[uint16], filename string) error {
+	file, err := CreateAtomic(filename)
 	if err != nil {
 		return err
 	}
@@ -26,28 +21,21 @@ func WriteLZWFile(codes *vector.Vector, filename string) error {
 	defer file.Close()
 
 	writer := bufio.NewWriter(file)
-	for i := 0; i < codes.Size(); i++ {
-		code := codes.MustGet(i)
 
-		codeBytes := make([]byte, 2)
-		binary.BigEndian.PutUint16(codeBytes, code.(uint16))
-
-		_, err := writer.Write(codeBytes)
-
-		if err != nil {
-			return err
-		}
+	err = lzw.WriteCodes(writer, codes)
+	if err != nil {
+		return err
 	}
 
-	err = file.Sync()
+	err = writer.Flush()
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	return writer.Flush()
+	return file.Commit()
 }
 
-func ReadLZWFile(filename string) (*vector.Vector, error) {
+func ReadLZWFile(filename string) (*vector.Vector[uint16], error) {
 	absolutePath, err := filepath.Abs(filename)
 	if err != nil {
 		return nil, err
@@ -60,27 +48,10 @@ func ReadLZWFile(filename string) (*vector.Vector, error) {
 
 	defer file.Close()
 
-	codes := vector.New()
-
-	for {
-		buf := make([]byte, 2)
-		_, err := io.ReadAtLeast(file, buf, 2)
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			return nil, err
-		}
-
-		code := binary.BigEndian.Uint16(buf)
-
-		codes.Append(code)
-	}
-
-	return codes, nil
+	return lzw.ReadCodes(file)
 }
 
-func ReadFile(filename string) (*vector.Vector, error) {
+func ReadFile(filename string) (*vector.Vector[byte], error) {
 	absolutePath, err := filepath.Abs(filename)
 	if err != nil {
 		return nil, err
@@ -93,41 +64,61 @@ func ReadFile(filename string) (*vector.Vector, error) {
 
 	defer file.Close()
 
-	bytes, err := ioutil.ReadAll(file)
+	return Read(file)
+}
+
+// Read reads all bytes from r, such as the standard input.
+func Read(r io.Reader) (*vector.Vector[byte], error) {
+	bytes, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
 
-	byteVector := vector.New(0, uint(len(bytes)))
-	for _, b := range bytes {
-		byteVector.Append(b)
-	}
-
-	return byteVector, nil
+	return vector.FromSlice(bytes), nil
 }
 
-func WriteFile(byteVector *vector.Vector, filename string) error {
+// ReadFileHeader reads at most n bytes from the beginning of the file.
+func ReadFileHeader(filename string, n int) (*vector.Vector[byte], error) {
 	absolutePath, err := filepath.Abs(filename)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	file, err := os.Create(absolutePath)
+	file, err := os.Open(absolutePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer file.Close()
 
-	bytes := make([]byte, 0, byteVector.Size())
-	for i := 0; i < byteVector.Size(); i++ {
-		bytes = append(bytes, byteVector.MustGet(i).(byte))
+	bytes, err := ioutil.ReadAll(io.LimitReader(file, int64(n)))
+	if err != nil {
+		return nil, err
+	}
+
+	return vector.FromSlice(bytes), nil
+}
+
+// WriteFile writes the bytes into the file atomically, so that the file is
+// either replaced with the complete bytes or left untouched.
+func WriteFile(byteVector *vector.Vector[byte], filename string) error {
+	file, err := CreateAtomic(filename)
+	if err != nil {
+		return err
 	}
 
-	_, err = file.Write(bytes)
+	defer file.Close()
+
+	err = Write(file, byteVector)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	return file.Sync()
+	return file.Commit()
+}
+
+// Write writes the bytes into w, such as the standard output.
+func Write(w io.Writer, byteVector *vector.Vector[byte]) error {
+	_, err := w.Write(byteVector.Slice())
+	return err
 }
@@ -0,0 +1,158 @@
+package fileio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mjjs/gompressor/datastructure/vector"
+)
+
+func bytesToVector(b []byte) *vector.Vector[byte] {
+	v := vector.New[byte]()
+	v.Append(b...)
+
+	return v
+}
+
+func TestWriteFileReplacesFileAtomically(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "file")
+
+	os.WriteFile(filename, []byte("old contents"), 0600)
+
+	if err := WriteFile(bytesToVector([]byte("new")), filename); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if contents, _ := os.ReadFile(filename); !bytes.Equal(contents, []byte("new")) {
+		t.Errorf("Expected %q, got %q", "new", contents)
+	}
+
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode %s, got %s", os.FileMode(0600), info.Mode().Perm())
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected %d file, got %d", 1, len(entries))
+	}
+}
+
+func TestWriteFileReturnsErrorOnMissingDirectory(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "missing", "file")
+
+	if err := WriteFile(bytesToVector([]byte("data")), filename); err == nil {
+		t.Error("Expected an error, got nil")
+	}
+}
+
+func TestAtomicFileLeavesDestinationUntouchedUntilCommit(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "file")
+
+	os.WriteFile(filename, []byte("old"), 0644)
+
+	file, err := CreateAtomic(filename)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	file.Write([]byte("partial"))
+
+	if err := file.Close(); err != nil {
+		t.Errorf("Expected nil error, got %s", err)
+	}
+
+	if contents, _ := os.ReadFile(filename); !bytes.Equal(contents, []byte("old")) {
+		t.Errorf("Expected %q, got %q", "old", contents)
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected the temporary file to be removed, got %d files", len(entries))
+	}
+
+	if err := file.Commit(); err == nil {
+		t.Error("Expected an error when committing a closed file, got nil")
+	}
+}
+
+func TestLZWFileRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "codes.lzw")
+	codes := vector.New[uint16]()
+	codes.Append(65535, 84, 79, 256, 258)
+
+	if err := WriteLZWFile(codes, filename); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	read, err := ReadLZWFile(filename)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if read.Size() != codes.Size() {
+		t.Fatalf("Expected %d codes, got %d", codes.Size(), read.Size())
+	}
+
+	for i := 0; i < codes.Size(); i++ {
+		if codes.MustGet(i) != read.MustGet(i) {
+			t.Errorf("Expected %d, got %d", codes.MustGet(i), read.MustGet(i))
+		}
+	}
+}
+
+func TestMapFileReturnsContents(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name     string
+		contents []byte
+	}{
+		{name: "file", contents: []byte("TOBEORNOTTOBEORTOBEORNOT")},
+		{name: "empty", contents: []byte{}},
+	}
+
+	for _, testCase := range testCases {
+		filename := filepath.Join(dir, testCase.name)
+		os.WriteFile(filename, testCase.contents, 0644)
+
+		file, err := MapFile(filename)
+		if err != nil {
+			t.Fatalf("%s: Expected nil error, got %s", testCase.name, err)
+		}
+
+		if actual := file.Vector().Slice(); !bytes.Equal(testCase.contents, actual) {
+			t.Errorf("%s: Expected %q, got %q", testCase.name, testCase.contents, actual)
+		}
+
+		if err := file.Close(); err != nil {
+			t.Errorf("%s: Expected nil error, got %s", testCase.name, err)
+		}
+	}
+}
+
+func TestMapFileReturnsErrorOnMissingFile(t *testing.T) {
+	if _, err := MapFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error, got nil")
+	}
+}
+
+func TestWriteFileMappedWritesBytes(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "file")
+
+	for _, contents := range [][]byte{[]byte("a longer old file"), []byte("new"), {}} {
+		if err := WriteFileMapped(bytesToVector(contents), filename); err != nil {
+			t.Fatalf("Expected nil error, got %s", err)
+		}
+
+		if actual, _ := os.ReadFile(filename); !bytes.Equal(contents, actual) {
+			t.Errorf("Expected %q, got %q", contents, actual)
+		}
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected %d file, got %d", 1, len(entries))
+	}
+}
@@ -0,0 +1,133 @@
+package fileio
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/mjjs/gompressor/datastructure/vector"
+)
+
+// errMmapUnsupported is returned by mmap on platforms without memory mapped
+// files.
+var errMmapUnsupported = errors.New("memory mapped files are not supported")
+
+// MappedFile holds the contents of a file mapped into memory read-only. The
+// contents are read into memory instead when the file can not be mapped, such
+// as when the file is a pipe or empty.
+type MappedFile struct {
+	data   []byte
+	mapped bool
+}
+
+// MapFile maps the file into memory, or reads it into memory if the file can
+// not be mapped. The file must not be truncated while it is mapped, as
+// accessing the missing pages crashes the program.
+func MapFile(filename string) (*MappedFile, error) {
+	absolutePath, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// The mapping stays valid after the file has been closed.
+	file, err := os.Open(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	size := info.Size()
+
+	if info.Mode().IsRegular() && size > 0 && int64(int(size)) == size {
+		if data, err := mmap(file, int(size), false); err == nil {
+			return &MappedFile{data: data, mapped: true}, nil
+		}
+	}
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MappedFile{data: data}, nil
+}
+
+// Vector returns the contents of the file without copying them. The vector
+// must not be modified, and it must not be used after Close.
+func (m *MappedFile) Vector() *vector.Vector[byte] {
+	return vector.FromSlice(m.data)
+}
+
+// Mapped reports whether the file is mapped into memory instead of having
+// been read into memory.
+func (m *MappedFile) Mapped() bool {
+	return m.mapped
+}
+
+// Close unmaps the file.
+func (m *MappedFile) Close() error {
+	data, mapped := m.data, m.mapped
+	m.data, m.mapped = nil, false
+
+	if !mapped {
+		return nil
+	}
+
+	return munmap(data)
+}
+
+// WriteFileMapped writes the bytes into the file atomically like WriteFile,
+// but copies them into the file through a memory mapping of the final size.
+// The bytes are written with regular I/O when the file can not be mapped.
+func WriteFileMapped(byteVector *vector.Vector[byte], filename string) error {
+	size := byteVector.Size()
+	if size == 0 {
+		return WriteFile(byteVector, filename)
+	}
+
+	file, err := CreateAtomic(filename)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	// Writing into unallocated pages of a mapping crashes the program if the
+	// disk is full, so the mapping is only used when the space is allocated.
+	if err := preallocate(file.File, int64(size)); err != nil {
+		return writeAndCommit(file, byteVector)
+	}
+
+	data, err := mmap(file.File, size, true)
+	if err != nil {
+		return writeAndCommit(file, byteVector)
+	}
+
+	copy(data, byteVector.Slice())
+
+	if err := munmap(data); err != nil {
+		return err
+	}
+
+	// Syncing the file flushes the pages written through the mapping.
+	return file.Commit()
+}
+
+func writeAndCommit(file *AtomicFile, byteVector *vector.Vector[byte]) error {
+	if _, err := file.WriteAt(byteVector.Slice(), 0); err != nil {
+		return err
+	}
+
+	if err := file.Truncate(int64(byteVector.Size())); err != nil {
+		return err
+	}
+
+	return file.Commit()
+}
@@ -0,0 +1,12 @@
+package fileio
+
+import (
+	"os"
+	"syscall"
+)
+
+// preallocate allocates the disk space for size bytes of the file and sets
+// the size of the file.
+func preallocate(file *os.File, size int64) error {
+	return syscall.Fallocate(int(file.Fd()), 0, 0, size)
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package fileio
+
+import (
+	"errors"
+	"os"
+)
+
+// preallocate allocates the disk space for size bytes of the file and sets
+// the size of the file. The space can not be allocated without extending the
+// file sparsely on this platform, so the file is never written through a
+// mapping.
+func preallocate(file *os.File, size int64) error {
+	return errors.New("preallocating files is not supported")
+}
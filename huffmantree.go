@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mjjs/gompressor/algorithm/huffman"
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/parallel"
+	"github.com/mjjs/gompressor/seekable"
+)
+
+// formatDOT is the format of the huffman-tree command which writes the tree
+// instead of the table of the codes.
+const formatDOT = "dot"
+
+// Kinds of input of the huffman-tree command.
+const (
+	inputAuto       = "auto"
+	inputRaw        = "raw"
+	inputCompressed = "huff"
+)
+
+var errMultipleTrees = errors.New("files compressed in blocks have a tree for each block")
+
+func runHuffmanTree(name string, args []string) int {
+	flags := newFlagSet(name)
+	formatFlag := addChoiceFlag(flags, "format", huffman.FormatText,
+		[]string{formatDOT, huffman.FormatText, huffman.FormatCSV, huffman.FormatJSON},
+		"output `format`: dot, text, csv or json")
+	inputFlag := addChoiceFlag(flags, "input", inputAuto,
+		[]string{inputAuto, inputRaw, inputCompressed},
+		"`kind` of the input: raw data, huff for data compressed with Huffman coding, or auto to choose based on the extension of the file")
+	outputFlag := flags.String("o", stdio, "write the output into the `file`")
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	filename := stdio
+	if flags.NArg() == 1 {
+		filename = flags.Arg(0)
+	}
+
+	data, err := readInput(filename)
+	if err != nil {
+		return fail(fmt.Errorf("input file could not be read: %w", err))
+	}
+
+	tree, err := readHuffmanTree(data, isCompressedInput(filename, *inputFlag))
+	if err != nil {
+		return fail(fmt.Errorf("%s: %w", displayName(filename, true), err))
+	}
+
+	out, err := createOutput(*outputFlag)
+	if err != nil {
+		return fail(err)
+	}
+	defer out.Close()
+
+	if *formatFlag == formatDOT {
+		err = tree.WriteDOT(out)
+	} else {
+		err = huffman.WriteCodeTable(out, tree.Codes(), *formatFlag)
+	}
+
+	if err == nil {
+		err = out.Commit()
+	}
+
+	if err != nil {
+		return fail(err)
+	}
+
+	return 0
+}
+
+// isCompressedInput reports whether the input of the huffman-tree command is
+// compressed data.
+func isCompressedInput(filename string, kind string) bool {
+	switch kind {
+	case inputRaw:
+		return false
+	case inputCompressed:
+		return true
+	}
+
+	registration, ok := codec.LookupAlgorithm(codec.Huffman)
+
+	return ok && filename != stdio && filepath.Ext(filename) == registration.Extension
+}
+
+// readHuffmanTree builds the prefix tree of raw data, or reads the tree of a
+// single stream compressed with Huffman coding.
+func readHuffmanTree(data *vector.Vector[byte], compressed bool) (*huffman.Tree, error) {
+	if !compressed {
+		return huffman.BuildTree(data), nil
+	}
+
+	if seekable.IsSeekable(data) || parallel.IsBlockFormat(data) {
+		return nil, errMultipleTrees
+	}
+
+	return huffman.ReadTree(data)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mjjs/gompressor/algorithm/huffman"
+	"github.com/mjjs/gompressor/algorithm/lzw"
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/parallel"
+	"github.com/mjjs/gompressor/seekable"
+)
+
+// fileInfo describes a compressed file.
+type fileInfo struct {
+	format    string
+	algorithm string
+	blocks    int
+
+	compressedSize int
+	// originalSize is negative when the format does not store it.
+	originalSize int
+
+	// The parameters stored in a single compressed stream. dictionarySize
+	// is zero and huffmanHeader nil for the other algorithms.
+	dictionarySize lzw.DictionarySize
+	huffmanHeader  *huffman.Header
+}
+
+func (i fileInfo) String() string {
+	s := fmt.Sprintf("  format:      %s\n  algorithm:   %s\n", i.format, i.algorithm)
+
+	if i.blocks > 0 {
+		s += fmt.Sprintf("  blocks:      %d\n", i.blocks)
+	}
+
+	s += fmt.Sprintf("  compressed:  %d bytes\n", i.compressedSize)
+
+	if i.originalSize >= 0 {
+		s += fmt.Sprintf("  original:    %d bytes\n", i.originalSize)
+	} else {
+		s += "  original:    unknown\n"
+	}
+
+	if i.originalSize > 0 {
+		s += fmt.Sprintf("  ratio:       %.2f%%\n", float64(i.compressedSize)/float64(i.originalSize)*100)
+	}
+
+	if i.dictionarySize != 0 {
+		s += fmt.Sprintf("  dictionary:  %d codes\n", i.dictionarySize)
+	}
+
+	if i.huffmanHeader != nil {
+		s += fmt.Sprintf("  symbols:     %d\n  code bits:   %d\n", i.huffmanHeader.Symbols(), i.huffmanHeader.CodeBits)
+		s += codeLengthTable(i.huffmanHeader.CodeLengths)
+	}
+
+	return s
+}
+
+// codeLengthTable returns a table of the bytes having a code of each length.
+func codeLengthTable(codeLengths map[byte]int) string {
+	symbols := map[int][]byte{}
+	lengths := []int{}
+
+	for value, length := range codeLengths {
+		if _, ok := symbols[length]; !ok {
+			lengths = append(lengths, length)
+		}
+
+		symbols[length] = append(symbols[length], value)
+	}
+
+	sort.Ints(lengths)
+
+	s := "  code lengths:\n"
+
+	for _, length := range lengths {
+		values := symbols[length]
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			quoted[i] = fmt.Sprintf("%q", value)
+		}
+
+		s += fmt.Sprintf("    %3d bits: %s\n", length, strings.Join(quoted, " "))
+	}
+
+	return s
+}
+
+func runInfo(name string, args []string) int {
+	flags := newFlagSet(name)
+	codecFlags := addCodecFlags(flags, "")
+	flags.Parse(args)
+
+	c, err := codecFlags.codec()
+	if err != nil {
+		return fail(err)
+	}
+
+	exitCode := 0
+
+	for _, file := range inputFiles(flags.Args()) {
+		info, err := readFileInfo(file, c)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", displayName(file, true), err)
+			exitCode = 1
+
+			continue
+		}
+
+		fmt.Printf("%s:\n%s", displayName(file, true), info)
+	}
+
+	return exitCode
+}
+
+// readFileInfo recognizes the format of the file and reads the information
+// stored in its headers. The algorithm of a single compressed stream is chosen
+// based on the extension of the file, unless the codec is given.
+func readFileInfo(filename string, c codec.Codec) (fileInfo, error) {
+	data, err := readInput(filename)
+	if err != nil {
+		return fileInfo{}, fmt.Errorf("input file could not be read: %w", err)
+	}
+
+	if c == nil && filename != stdio {
+		c, _ = codecForFile(filename)
+	}
+
+	return readInfo(data, c)
+}
+
+// readInfo recognizes the format of the compressed data and reads the
+// information stored in its headers. The codec is the algorithm of a single
+// compressed stream, which does not store its algorithm.
+func readInfo(data *vector.Vector[byte], c codec.Codec) (fileInfo, error) {
+	info := fileInfo{compressedSize: data.Size(), originalSize: -1}
+
+	switch {
+	case seekable.IsSeekable(data):
+		reader, err := seekable.NewReader(bytes.NewReader(data.Slice()), int64(data.Size()))
+		if err != nil {
+			return fileInfo{}, err
+		}
+
+		info.format = "seekable"
+		info.algorithm = reader.Algorithm().String()
+		info.blocks = reader.Blocks()
+		info.originalSize = int(reader.Size())
+
+	case parallel.IsBlockFormat(data):
+		index, err := parallel.ReadIndex(data)
+		if err != nil {
+			return fileInfo{}, err
+		}
+
+		info.format = "blocks"
+		info.algorithm = index.Algorithm.String()
+		info.blocks = len(index.Blocks)
+		info.originalSize = index.UncompressedSize()
+
+	case c == nil:
+		return fileInfo{}, errUnknownAlgorithm
+
+	default:
+		info.format = "stream"
+		info.algorithm = c.Algorithm().String()
+
+		if err := readStreamInfo(data, c.Algorithm(), &info); err != nil {
+			return fileInfo{}, err
+		}
+	}
+
+	return info, nil
+}
+
+// readStreamInfo reads the parameters stored at the beginning of a single
+// compressed stream into info.
+func readStreamInfo(data *vector.Vector[byte], algorithm codec.Algorithm, info *fileInfo) error {
+	// Empty data is compressed into an empty stream.
+	if data.Size() == 0 {
+		info.originalSize = 0
+		return nil
+	}
+
+	switch algorithm {
+	case codec.LZW:
+		dictionarySize, err := lzw.ReadDictionarySize(bytes.NewReader(data.Slice()))
+		if err != nil {
+			return err
+		}
+
+		info.dictionarySize = dictionarySize
+
+	case codec.Huffman:
+		header, err := huffman.ReadHeader(data)
+		if err != nil {
+			return err
+		}
+
+		info.huffmanHeader = &header
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+// Package limit protects decompression against decompression bombs, which are
+// small inputs crafted to decompress into enormous outputs, by limiting the
+// size of the decompressed data.
+package limit
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrExceeded is returned when decompressed data exceeds the limits.
+var ErrExceeded = errors.New("decompressed data exceeds the limit")
+
+// Limits restricts the size of decompressed data. The zero value does not
+// restrict it.
+type Limits struct {
+	// MaxOutput is the maximum number of decompressed bytes. Zero means no
+	// limit.
+	MaxOutput int
+	// MaxRatio is the maximum number of decompressed bytes per compressed
+	// byte. Zero means no limit.
+	MaxRatio float64
+}
+
+// Check returns an error wrapping ErrExceeded if compressed data of the given
+// size decompressing into outputSize bytes exceeds the limits. Decoders call
+// Check as the output grows, so that they can stop before running out of
+// memory.
+func (l Limits) Check(compressedSize int, outputSize int) error {
+	if l.MaxOutput > 0 && outputSize > l.MaxOutput {
+		return fmt.Errorf("%w: more than %d bytes", ErrExceeded, l.MaxOutput)
+	}
+
+	if l.MaxRatio > 0 && float64(outputSize) > l.MaxRatio*float64(compressedSize) {
+		return fmt.Errorf("%w: %d bytes expand beyond the ratio %g", ErrExceeded, compressedSize, l.MaxRatio)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package limit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	testCases := []struct {
+		limits         Limits
+		compressedSize int
+		outputSize     int
+		exceeded       bool
+	}{
+		{limits: Limits{}, compressedSize: 1, outputSize: 1 << 30, exceeded: false},
+		{limits: Limits{MaxOutput: 100}, compressedSize: 1, outputSize: 100, exceeded: false},
+		{limits: Limits{MaxOutput: 100}, compressedSize: 1, outputSize: 101, exceeded: true},
+		{limits: Limits{MaxRatio: 10}, compressedSize: 10, outputSize: 100, exceeded: false},
+		{limits: Limits{MaxRatio: 10}, compressedSize: 10, outputSize: 101, exceeded: true},
+		{limits: Limits{MaxOutput: 1000, MaxRatio: 10}, compressedSize: 10, outputSize: 101, exceeded: true},
+		{limits: Limits{MaxOutput: 50, MaxRatio: 10}, compressedSize: 10, outputSize: 51, exceeded: true},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.limits.Check(testCase.compressedSize, testCase.outputSize)
+
+		if testCase.exceeded && !errors.Is(err, ErrExceeded) {
+			t.Errorf("%+v: Expected %s, got %v", testCase, ErrExceeded, err)
+		}
+
+		if !testCase.exceeded && err != nil {
+			t.Errorf("%+v: Expected nil error, got %s", testCase, err)
+		}
+	}
+}
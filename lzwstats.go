@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mjjs/gompressor/algorithm/lzw"
+	"github.com/mjjs/gompressor/codec"
+)
+
+// maxPhraseText is the number of bytes of a phrase shown by the lzw-stats
+// command.
+const maxPhraseText = 60
+
+func runLZWStats(name string, args []string) int {
+	flags := newFlagSet(name)
+	dictSize := lzwDictSizeOption()
+	dictSizeFlag := flags.String(dictSize.Name, dictSize.Default, dictSize.Description)
+	longestFlag := flags.Int("longest", 10, "show the `n` longest phrases")
+	generationsFlag := flags.Bool("generations", false, "show the utilisation of the dictionary between each reset")
+	jsonFlag := flags.Bool("json", false, "print the statistics as JSON")
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	size, err := lzw.ParseDictionarySize(*dictSizeFlag)
+	if err != nil {
+		return fail(err)
+	}
+
+	filename := stdio
+	if flags.NArg() == 1 {
+		filename = flags.Arg(0)
+	}
+
+	data, err := readInput(filename)
+	if err != nil {
+		return fail(fmt.Errorf("input file could not be read: %w", err))
+	}
+
+	stats := lzw.NewStatistics(size, *longestFlag)
+
+	if _, err := lzw.CompressObserved(data, size, stats); err != nil {
+		return fail(err)
+	}
+
+	if *jsonFlag {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(stats); err != nil {
+			return fail(err)
+		}
+
+		return 0
+	}
+
+	printLZWStats(os.Stdout, stats, *generationsFlag)
+
+	return 0
+}
+
+func printLZWStats(w io.Writer, stats *lzw.Statistics, generations bool) {
+	fmt.Fprintf(w, "input:          %d bytes\n", stats.InputSize)
+	fmt.Fprintf(w, "dictionary:     %d codes\n", stats.DictionarySize)
+
+	// The dictionary size is stored as the first code of non-empty output.
+	compressedSize := 0
+	if stats.Codes > 0 {
+		compressedSize = (stats.Codes + 1) * 2
+	}
+
+	fmt.Fprintf(w, "codes:          %d (%d bytes)\n", stats.Codes, compressedSize)
+	fmt.Fprintf(w, "resets:         %d\n", len(stats.Resets))
+
+	minimum, average, maximum := utilisation(stats.Generations)
+	fmt.Fprintf(w, "utilisation:    %.1f%% average, %.1f%% min, %.1f%% max\n", average, minimum, maximum)
+
+	if generations {
+		fmt.Fprintln(w, "\ngenerations:")
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "offset\tentries\tcodes\tused codes\tutilisation\t")
+
+		for _, g := range stats.Generations {
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%.1f%%\t\n", g.Offset, g.Entries, g.Codes, g.UsedCodes, g.Utilisation())
+		}
+
+		tw.Flush()
+	}
+
+	if len(stats.PhraseLengths) > 0 {
+		fmt.Fprintln(w, "\nphrase lengths:")
+	}
+
+	lengths := make([]int, 0, len(stats.PhraseLengths))
+	mostCodes := 0
+
+	for length, count := range stats.PhraseLengths {
+		lengths = append(lengths, length)
+
+		if count > mostCodes {
+			mostCodes = count
+		}
+	}
+
+	sort.Ints(lengths)
+
+	for _, length := range lengths {
+		count := stats.PhraseLengths[length]
+		bar := strings.Repeat("#", (count*40+mostCodes-1)/mostCodes)
+		fmt.Fprintf(w, "  %4d  %-40s %d\n", length, bar, count)
+	}
+
+	if len(stats.LongestPhrases) > 0 {
+		fmt.Fprintln(w, "\nlongest phrases:")
+
+		for _, phrase := range stats.LongestPhrases {
+			fmt.Fprintf(w, "  %4d  %s\n", phrase.Length, phraseText(phrase.Bytes))
+		}
+	}
+}
+
+// utilisation returns the minimum, average and maximum utilisation of the
+// dictionary generations.
+func utilisation(generations []lzw.Generation) (float64, float64, float64) {
+	if len(generations) == 0 {
+		return 0, 0, 0
+	}
+
+	minimum, maximum, total := 100.0, 0.0, 0.0
+
+	for _, g := range generations {
+		u := g.Utilisation()
+		total += u
+
+		if u < minimum {
+			minimum = u
+		}
+
+		if u > maximum {
+			maximum = u
+		}
+	}
+
+	return minimum, total / float64(len(generations)), maximum
+}
+
+// lzwDictSizeOption returns the dictionary size option of the lzw codec, so
+// that the lzw-stats command accepts the same sizes.
+func lzwDictSizeOption() codec.OptionInfo {
+	registration, _ := codec.LookupAlgorithm(codec.LZW)
+
+	for _, option := range registration.Options {
+		if option.Name == "dict-size" {
+			return option
+		}
+	}
+
+	return codec.OptionInfo{}
+}
+
+// phraseText quotes the phrase, shortening it if it is long.
+func phraseText(phrase []byte) string {
+	if len(phrase) > maxPhraseText {
+		return strconv.Quote(string(phrase[:maxPhraseText])) + "..."
+	}
+
+	return strconv.Quote(string(phrase))
+}
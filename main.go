@@ -2,129 +2,381 @@ package main
 
 import (
 	"flag"
-	"log"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
 
-	"github.com/mjjs/gompressor/algorithm/huffman"
-	"github.com/mjjs/gompressor/algorithm/lzw"
-	"github.com/mjjs/gompressor/fileio"
-	"github.com/mjjs/gompressor/ui"
+	"github.com/mjjs/gompressor/batch"
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/limit"
 )
 
-func main() {
-	tuiFlag := flag.Bool("tui", false, "use TUI")
-	compressFlag := flag.Bool("compress", false, "compress the input file")
-	decompressFlag := flag.Bool("decompress", false, "decompress the input file")
-	huffmanFlag := flag.Bool("huffman", false, "use huffman algorithm")
-	lzwFlag := flag.Bool("lzw", false, "use lzw algorithm")
-	inputFileFlag := flag.String("in", "", "input file")
-	outputFileFlag := flag.String("out", "", "output file")
+// command is a subcommand of the program.
+type command struct {
+	name        string
+	arguments   string
+	description string
+	run         func(name string, args []string) int
+}
 
-	flag.Parse()
+var commands []command
 
-	if *tuiFlag {
-		ui.New().Run()
+func init() {
+	commands = []command{
+		{
+			name:        "compress",
+			arguments:   "[flags] [file...]",
+			description: "Compress the files. Each file is compressed into a file with the extension of the algorithm added to its name. Without files, or with the file -, the standard input is compressed into the standard output.",
+			run:         runCompress,
+		},
+		{
+			name:        "decompress",
+			arguments:   "[flags] [file...]",
+			description: "Decompress the files. The extension of the algorithm is removed from the name of each file, and the algorithm is chosen based on the extension unless given with -a. Without files, or with the file -, the standard input is decompressed into the standard output.",
+			run:         runDecompress,
+		},
+		{
+			name:        "test",
+			arguments:   "[flags] [file...]",
+			description: "Test the integrity of the compressed files by decompressing them in memory without writing anything to disk. Without files, or with the file -, the standard input is tested.",
+			run:         runTest,
+		},
+		{
+			name:        "info",
+			arguments:   "[flags] file...",
+			description: "Show information about the compressed files without decompressing them.",
+			run:         runInfo,
+		},
+		{
+			name:        "bench",
+			arguments:   "[flags] file...",
+			description: "Measure the compression ratio and speed of the algorithms on the files.",
+			run:         runBench,
+		},
+		{
+			name:        "huffman-tree",
+			arguments:   "[flags] [file]",
+			description: "Show the Huffman prefix tree of the file as a Graphviz DOT graph or a table of the codes. The tree is built from the contents of the file, or read from a file compressed with Huffman coding. Without a file, or with the file -, the standard input is read.",
+			run:         runHuffmanTree,
+		},
+		{
+			name:        "lzw-stats",
+			arguments:   "[flags] [file]",
+			description: "Compress the file with LZW in memory and show how the dictionary is used: the dictionary resets, code utilisation, phrase lengths and the longest phrases. Without a file, or with the file -, the standard input is read.",
+			run:         runLZWStats,
+		},
+		{
+			name:        "archive",
+			arguments:   "create|list|extract [flags] ...",
+			description: "Create, list and extract archives of multiple files and directories.",
+			run:         runArchive,
+		},
+		{
+			name:        "tui",
+			arguments:   "[flags]",
+			description: "Start the text-based user interface.",
+			run:         runTUI,
+		},
 	}
+}
 
-	if *compressFlag && *decompressFlag {
-		log.Fatal("Only compress or decompress flag can be provided")
-	} else if !*compressFlag && !*decompressFlag {
-		log.Fatal("Compress or decompress flag must be provided")
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
 	}
 
-	if *inputFileFlag == "" || *outputFileFlag == "" {
-		log.Fatal("Input and output files must be provided")
+	name := os.Args[1]
+
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(os.Args) > 2 {
+			if c, ok := findCommand(os.Args[2]); ok {
+				os.Exit(c.run(c.name, []string{"-help"}))
+			}
+		}
+
+		usage()
+		os.Exit(0)
 	}
 
-	if *huffmanFlag && *lzwFlag {
-		log.Fatal("Only supply one of the algorithm flags")
-	} else if !*huffmanFlag && !*lzwFlag {
-		log.Fatal("Supply one of the algorithm flags (-huffman, -lzw)")
+	c, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
 	}
 
-	if *compressFlag {
-		if *huffmanFlag {
-			compressHuffman(*inputFileFlag, *outputFileFlag)
-		} else {
-			compressLZW(*inputFileFlag, *outputFileFlag)
+	os.Exit(c.run(c.name, os.Args[2:]))
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags] [arguments]\n\nCommands:\n", os.Args[0])
+
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s%s\n", c.name, c.description)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nRun '%s help <command>' for the flags of a command.\n", os.Args[0])
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
 		}
-	} else {
-		if *huffmanFlag {
-			decompressHuffman(*inputFileFlag, *outputFileFlag)
-		} else {
-			decompressLZW(*inputFileFlag, *outputFileFlag)
+	}
+
+	return command{}, false
+}
+
+// newFlagSet returns the flag set of the command, whose usage describes the
+// command and its flags.
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	c, _ := findCommand(strings.Fields(name)[0])
+
+	arguments, ok := subcommandArguments[name]
+	if !ok {
+		arguments = c.arguments
+	}
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s %s\n\n%s\n", os.Args[0], name, arguments, c.description)
+
+		hasFlags := false
+		flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+
+		if hasFlags {
+			fmt.Fprintf(flags.Output(), "\nFlags:\n")
+			flags.PrintDefaults()
 		}
 	}
+
+	return flags
 }
 
-func compressHuffman(inputFilename string, outputFilename string) {
-	bytes, err := fileio.ReadFile(inputFilename)
-	if err != nil {
-		log.Fatalf("Input file could not be read: %s", err)
+// optionsFlag collects repeated key=value options.
+type optionsFlag map[string]string
+
+func (o optionsFlag) String() string {
+	options := make([]string, 0, len(o))
+	for key, value := range o {
+		options = append(options, key+"="+value)
 	}
 
-	compressed := huffman.Compress(bytes)
+	return strings.Join(options, ",")
+}
 
-	err = fileio.WriteFile(compressed, outputFilename)
-	if err != nil {
-		log.Fatalf("Could not write compressed data: %s", err)
+func (o optionsFlag) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
 	}
 
-	log.Printf("Wrote %d bytes to %s", compressed.Size(), outputFilename)
+	o[parts[0]] = parts[1]
+
+	return nil
+}
+
+// choiceFlag is a string flag which accepts only one of the choices.
+type choiceFlag struct {
+	value   string
+	choices []string
+}
+
+// addChoiceFlag adds a flag into flags, which accepts only one of the choices.
+func addChoiceFlag(flags *flag.FlagSet, name string, value string, choices []string, usage string) *string {
+	f := &choiceFlag{value: value, choices: choices}
+	flags.Var(f, name, usage)
+
+	return &f.value
+}
+
+func (c *choiceFlag) String() string {
+	return c.value
 }
 
-func decompressHuffman(inputFilename string, outputFilename string) {
-	bytes, err := fileio.ReadFile(inputFilename)
-	if err != nil {
-		log.Fatalf("Input file could not be read: %s", err)
+func (c *choiceFlag) Set(value string) error {
+	for _, choice := range c.choices {
+		if value == choice {
+			c.value = value
+			return nil
+		}
 	}
 
-	decompressed, err := huffman.Decompress(bytes)
-	if err != nil {
-		log.Fatalf("Could not decompress data: %s", err)
+	return fmt.Errorf("expected one of %s", strings.Join(c.choices, ", "))
+}
+
+// codecFlags are the flags used for choosing the algorithm and its options.
+type codecFlags struct {
+	algorithm string
+	options   optionsFlag
+}
+
+// addCodecFlags adds the -a/--algorithm and -O/--option flags into flags.
+func addCodecFlags(flags *flag.FlagSet, defaultAlgorithm string) *codecFlags {
+	f := &codecFlags{algorithm: defaultAlgorithm, options: optionsFlag{}}
+
+	var names, options []string
+
+	for _, registration := range codec.Registrations() {
+		names = append(names, registration.Name)
+
+		for _, option := range registration.Options {
+			options = append(options, fmt.Sprintf("\n  %s: %s=%s (default %s)",
+				registration.Name, option.Name, option.Description, option.Default))
+		}
 	}
 
-	err = fileio.WriteFile(decompressed, outputFilename)
-	if err != nil {
-		log.Fatalf("Could not write decompressed data: %s", err)
+	algorithmUsage := "compression algorithm: " + strings.Join(names, ", ")
+	flags.StringVar(&f.algorithm, "a", defaultAlgorithm, algorithmUsage)
+	flags.StringVar(&f.algorithm, "algorithm", defaultAlgorithm, algorithmUsage)
+
+	optionUsage := "algorithm option as key=value, can be repeated" + strings.Join(options, "")
+	flags.Var(f.options, "O", optionUsage)
+	flags.Var(f.options, "option", optionUsage)
+
+	return f
+}
+
+// codec returns the codec chosen with the flags, or nil if no algorithm was
+// chosen.
+func (f *codecFlags) codec() (codec.Codec, error) {
+	if f.algorithm == "" {
+		if len(f.options) > 0 {
+			return nil, fmt.Errorf("algorithm options require an algorithm")
+		}
+
+		return nil, nil
 	}
 
-	log.Printf("Wrote %d bytes to %s", decompressed.Size(), outputFilename)
+	return codec.New(f.algorithm, f.options)
+}
+
+// sizeFlag is a size in bytes, which can be given with a K, M or G suffix for
+// kibibytes, mebibytes or gibibytes.
+type sizeFlag int
+
+func (s sizeFlag) String() string {
+	return strconv.Itoa(int(s))
 }
 
-func compressLZW(inputFilename string, outputFilename string) {
-	bytes, err := fileio.ReadFile(inputFilename)
-	if err != nil {
-		log.Fatalf("Input file could not be read: %s", err)
+func (s *sizeFlag) Set(value string) error {
+	digits, multiplier := value, 1
+
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1 << 30
+	}
+
+	if multiplier != 1 {
+		digits = value[:len(value)-1]
+	}
+
+	size, err := strconv.Atoi(digits)
+	if err != nil || size < 0 || size > math.MaxInt/multiplier {
+		return fmt.Errorf("invalid size %q", value)
 	}
 
-	compressed, err := lzw.Compress(bytes)
-	if err != nil {
-		log.Fatalf("Could not compress data: %s", err)
+	*s = sizeFlag(size * multiplier)
+
+	return nil
+}
+
+// limitFlags are the flags used for limiting the size of decompressed data.
+type limitFlags struct {
+	maxOutput sizeFlag
+	maxRatio  float64
+}
+
+// addLimitFlags adds the -max-output and -max-ratio flags into flags.
+func addLimitFlags(flags *flag.FlagSet) *limitFlags {
+	f := &limitFlags{}
+
+	flags.Var(&f.maxOutput, "max-output", "maximum size of the decompressed data of a file in bytes, with an optional K, M or G suffix, 0 for no limit")
+	flags.Float64Var(&f.maxRatio, "max-ratio", 0, "maximum ratio of decompressed to compressed size of a file, 0 for no limit")
+
+	return f
+}
+
+// limits returns the limits chosen with the flags.
+func (f *limitFlags) limits() (limit.Limits, error) {
+	if f.maxRatio < 0 {
+		return limit.Limits{}, fmt.Errorf("the maximum ratio can not be negative")
 	}
 
-	err = fileio.WriteLZWFile(compressed, outputFilename)
-	if err != nil {
-		log.Fatalf("Could not write compressed data: %s", err)
+	return limit.Limits{MaxOutput: int(f.maxOutput), MaxRatio: f.maxRatio}, nil
+}
+
+// extension returns the file extension of the codec.
+func extension(c codec.Codec) string {
+	registration, _ := codec.LookupAlgorithm(c.Algorithm())
+	return registration.Extension
+}
+
+// codecForFile returns the codec whose extension the file has.
+func codecForFile(filename string) (codec.Codec, bool) {
+	for _, registration := range codec.Registrations() {
+		if strings.HasSuffix(filename, registration.Extension) && len(filename) > len(registration.Extension) {
+			c, err := codec.ForAlgorithm(registration.Algorithm)
+			return c, err == nil
+		}
 	}
 
-	log.Printf("Wrote %d bytes to %s", compressed.Size(), outputFilename)
+	return nil, false
 }
 
-func decompressLZW(inputFilename string, outputFilename string) {
-	bytes, err := fileio.ReadLZWFile(inputFilename)
-	if err != nil {
-		log.Fatalf("Input file could not be read: %s", err)
+// processFiles calls work for each of the files concurrently, prints the
+// result of each file and a summary of all the files when there are more than
+// one. The results are printed into the standard error, so that they are never
+// mixed with data written into the standard output. processFiles returns the
+// exit code of the command.
+func processFiles(paths []string, recursive bool, jobs int, work func(file string) batch.Result) int {
+	results := runFiles(paths, recursive, jobs, work)
+
+	for _, result := range results {
+		result.Input = displayName(result.Input, true)
+		if result.Output != "" {
+			result.Output = displayName(result.Output, false)
+		}
+
+		fmt.Fprintln(os.Stderr, result)
+	}
+
+	summary := batch.Summarize(results)
+	if len(results) > 1 {
+		fmt.Fprintln(os.Stderr, summary)
 	}
 
-	decompressed, err := lzw.Decompress(bytes)
-	if err != nil {
-		log.Fatalf("Could not decompress data: %s", err)
+	if summary.Failed > 0 {
+		return 1
 	}
 
-	err = fileio.WriteFile(decompressed, outputFilename)
-	if err != nil {
-		log.Fatalf("Could not write decompressed data: %s", err)
+	return 0
+}
+
+// runFiles calls work for each of the files concurrently, and returns the
+// results in order along with the paths which could not be expanded into
+// files.
+func runFiles(paths []string, recursive bool, jobs int, work func(file string) batch.Result) []batch.Result {
+	if len(paths) == 1 && paths[0] == stdio {
+		return batch.Run(paths, jobs, work)
 	}
 
-	log.Printf("Wrote %d bytes to %s", decompressed.Size(), outputFilename)
+	files, failures := batch.Files(paths, recursive)
+
+	return append(failures, batch.Run(files, jobs, work)...)
+}
+
+// fail prints the error of a command and returns the exit code for it.
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", os.Args[0], err)
+	return 1
 }
@@ -0,0 +1,358 @@
+// Package parallel implements compression of data split into independent
+// blocks, which are compressed and decompressed concurrently.
+//
+// The compressed data starts with a header containing the block index,
+// followed by the compressed blocks in order:
+//
+//	magic       4 bytes  "GMPB"
+//	version     1 byte
+//	algorithm   1 byte
+//	blocks      uint32
+//	index       blocks * (uncompressed size uint32, compressed size uint32)
+//	payload     the compressed blocks
+//
+// All integers are stored in big-endian byte order.
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/limit"
+	"github.com/mjjs/gompressor/progress"
+)
+
+// DefaultBlockSize is the amount of uncompressed bytes in a single block.
+const DefaultBlockSize int = 1 << 20
+
+const (
+	version    byte = 1
+	headerSize int  = 10
+	entrySize  int  = 8
+)
+
+// MagicSize is the amount of bytes IsBlockFormat needs to recognize the
+// block format.
+const MagicSize int = 4
+
+var magic = []byte("GMPB")
+
+// ErrInvalidBlockSize is returned when the block size is not positive or does
+// not fit into the block index.
+var ErrInvalidBlockSize = errors.New("invalid block size")
+
+// ErrInvalidHeader is returned when the data does not start with a valid
+// block format header.
+var ErrInvalidHeader = errors.New("invalid block header")
+
+// ErrCorruptIndex is returned when the block index does not match the data.
+var ErrCorruptIndex = errors.New("corrupt block index")
+
+type indexEntry struct {
+	uncompressedSize uint32
+	compressedSize   uint32
+}
+
+// Index describes the blocks of data compressed by Compress.
+type Index struct {
+	Algorithm codec.Algorithm
+	Blocks    []Block
+}
+
+// Block describes a single compressed block.
+type Block struct {
+	UncompressedSize int
+	CompressedSize   int
+}
+
+// UncompressedSize returns the total size of the data when decompressed.
+func (i Index) UncompressedSize() int {
+	size := 0
+	for _, block := range i.Blocks {
+		size += block.UncompressedSize
+	}
+
+	return size
+}
+
+// ReadIndex reads the block index of data compressed by Compress without
+// decompressing the blocks.
+func ReadIndex(compressed *vector.Vector[byte]) (Index, error) {
+	algorithm, entries, _, err := readHeader(compressed.Slice())
+	if err != nil {
+		return Index{}, err
+	}
+
+	index := Index{Algorithm: algorithm, Blocks: make([]Block, len(entries))}
+	for i, entry := range entries {
+		index.Blocks[i] = Block{
+			UncompressedSize: int(entry.uncompressedSize),
+			CompressedSize:   int(entry.compressedSize),
+		}
+	}
+
+	return index, nil
+}
+
+// IsBlockFormat reports whether data starts with the block format header.
+func IsBlockFormat(data *vector.Vector[byte]) bool {
+	return bytes.HasPrefix(data.Slice(), magic)
+}
+
+// Compress splits data into blocks of blockSize bytes and compresses them
+// with the codec using threads goroutines. If threads is less than one, a
+// goroutine is started for each CPU.
+func Compress(data *vector.Vector[byte], c codec.Codec, blockSize int, threads int) (*vector.Vector[byte], error) {
+	return CompressContext(context.Background(), data, c, blockSize, threads, nil)
+}
+
+// CompressContext compresses like Compress, but stops with the error of the
+// context when it is done. The progress is reported to report, which may be
+// nil, each time a block has been compressed.
+func CompressContext(ctx context.Context, data *vector.Vector[byte], c codec.Codec, blockSize int, threads int, report progress.Func) (*vector.Vector[byte], error) {
+	if blockSize <= 0 || int64(blockSize) > math.MaxUint32 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidBlockSize, blockSize)
+	}
+
+	input := data.Slice()
+	blocks := (len(input) + blockSize - 1) / blockSize
+	compressed := make([][]byte, blocks)
+
+	var mu sync.Mutex
+	read, written := 0, 0
+
+	err := run(blocks, threads, func(i int) error {
+		start := i * blockSize
+		end := start + blockSize
+		if end > len(input) {
+			end = len(input)
+		}
+
+		block := vector.New[byte](0, uint(end-start))
+		block.Append(input[start:end]...)
+
+		result, err := codec.CompressContext(ctx, c, block, nil)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", i, err)
+		}
+
+		compressed[i] = result.Slice()
+
+		mu.Lock()
+		read += end - start
+		written += result.Size()
+		report.Report(read, written)
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	size := headerSize + blocks*entrySize
+	for _, block := range compressed {
+		size += len(block)
+	}
+
+	output := vector.New[byte](0, uint(size))
+	output.Append(magic...)
+	output.Append(version, byte(c.Algorithm()))
+	output.Append(uint32Bytes(uint32(blocks))...)
+
+	for i, block := range compressed {
+		start := i * blockSize
+		end := start + blockSize
+		if end > len(input) {
+			end = len(input)
+		}
+
+		output.Append(uint32Bytes(uint32(end - start))...)
+		output.Append(uint32Bytes(uint32(len(block)))...)
+	}
+
+	for _, block := range compressed {
+		output.Append(block...)
+	}
+
+	return output, nil
+}
+
+// Decompress decompresses data created by Compress using threads goroutines.
+// If threads is less than one, a goroutine is started for each CPU.
+func Decompress(compressed *vector.Vector[byte], threads int) (*vector.Vector[byte], error) {
+	return DecompressWithLimits(compressed, threads, limit.Limits{})
+}
+
+// DecompressWithLimits decompresses like Decompress, but returns an error
+// wrapping limit.ErrExceeded without decompressing anything if the sizes
+// stored in the index exceed the limits. Each block is decompressed with its
+// stored size as the limit, so that a block can not expand beyond it, and an
+// index storing sizes the blocks could not expand into is rejected.
+func DecompressWithLimits(compressed *vector.Vector[byte], threads int, limits limit.Limits) (*vector.Vector[byte], error) {
+	algorithm, index, payload, err := readHeader(compressed.Slice())
+	if err != nil {
+		return nil, err
+	}
+
+	registration, _ := codec.LookupAlgorithm(algorithm)
+
+	c, err := codec.ForAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make([]int, len(index))
+	offset, outputSize := 0, 0
+
+	for i, entry := range index {
+		if entry.uncompressedSize == 0 {
+			return nil, fmt.Errorf("%w: block %d is empty", ErrCorruptIndex, i)
+		}
+
+		if int64(entry.uncompressedSize) > int64(entry.compressedSize)*int64(registration.MaxExpansion) {
+			return nil, fmt.Errorf("%w: block %d of %d bytes can not decompress into %d bytes",
+				ErrCorruptIndex, i, entry.compressedSize, entry.uncompressedSize)
+		}
+
+		offsets[i] = offset
+		offset += int(entry.compressedSize)
+		outputSize += int(entry.uncompressedSize)
+	}
+
+	if offset != len(payload) {
+		return nil, fmt.Errorf("%w: blocks take %d bytes, payload has %d", ErrCorruptIndex, offset, len(payload))
+	}
+
+	if err := limits.Check(compressed.Size(), outputSize); err != nil {
+		return nil, err
+	}
+
+	// The blocks are concatenated only after all of them have been
+	// decompressed, so that memory is not allocated for the sizes stored in
+	// the index before the blocks have proven to expand into them.
+	results := make([]*vector.Vector[byte], len(index))
+
+	err = run(len(index), threads, func(i int) error {
+		start := offsets[i]
+		block := vector.New[byte](0, uint(index[i].compressedSize))
+		block.Append(payload[start : start+int(index[i].compressedSize)]...)
+
+		result, err := codec.DecompressWithLimits(c, block, limit.Limits{MaxOutput: int(index[i].uncompressedSize)})
+		if errors.Is(err, limit.ErrExceeded) {
+			return fmt.Errorf("%w: block %d decompressed into more than %d bytes",
+				ErrCorruptIndex, i, index[i].uncompressedSize)
+		}
+
+		if err != nil {
+			return fmt.Errorf("block %d: %w", i, err)
+		}
+
+		if result.Size() != int(index[i].uncompressedSize) {
+			return fmt.Errorf("%w: block %d decompressed into %d bytes, expected %d",
+				ErrCorruptIndex, i, result.Size(), index[i].uncompressedSize)
+		}
+
+		results[i] = result
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	decompressed := vector.New[byte](0, uint(outputSize))
+	for _, result := range results {
+		decompressed.Append(result.Slice()...)
+	}
+
+	return decompressed, nil
+}
+
+func readHeader(data []byte) (codec.Algorithm, []indexEntry, []byte, error) {
+	if len(data) < headerSize || !bytes.HasPrefix(data, magic) {
+		return 0, nil, nil, ErrInvalidHeader
+	}
+
+	if data[4] != version {
+		return 0, nil, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidHeader, data[4])
+	}
+
+	algorithm := codec.Algorithm(data[5])
+	if !algorithm.Valid() {
+		return 0, nil, nil, fmt.Errorf("%w: %d", codec.ErrInvalidAlgorithm, algorithm)
+	}
+
+	blocks := int(binary.BigEndian.Uint32(data[6:headerSize]))
+	if blocks > (len(data)-headerSize)/entrySize {
+		return 0, nil, nil, fmt.Errorf("%w: %d blocks do not fit into the data", ErrCorruptIndex, blocks)
+	}
+
+	index := make([]indexEntry, blocks)
+	for i := range index {
+		entry := data[headerSize+i*entrySize:]
+		index[i] = indexEntry{
+			uncompressedSize: binary.BigEndian.Uint32(entry[0:4]),
+			compressedSize:   binary.BigEndian.Uint32(entry[4:8]),
+		}
+	}
+
+	return algorithm, index, data[headerSize+blocks*entrySize:], nil
+}
+
+// run calls work for each of the n blocks using a pool of threads goroutines,
+// and returns the error of the first failed block.
+func run(n int, threads int, work func(i int) error) error {
+	if threads < 1 {
+		threads = runtime.NumCPU()
+	}
+
+	if threads > n {
+		threads = n
+	}
+
+	jobs := make(chan int)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	wg.Add(threads)
+
+	for t := 0; t < threads; t++ {
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				errs[i] = work(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func uint32Bytes(value uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, value)
+
+	return b
+}
@@ -0,0 +1,249 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/limit"
+)
+
+var codecs = []struct {
+	name  string
+	codec codec.Codec
+}{
+	{name: "Huffman", codec: mustCodec("huffman", nil)},
+	{name: "LZW", codec: mustCodec("lzw", nil)},
+	{name: "LZW with a small dictionary", codec: mustCodec("lzw", map[string]string{"dict-size": "xs"})},
+}
+
+func mustCodec(name string, options map[string]string) codec.Codec {
+	c, err := codec.New(name, options)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+func testInput() *vector.Vector[byte] {
+	input := vector.New[byte]()
+	for i := 0; i < 100; i++ {
+		input.Append([]byte("TOBEORNOTTOBEORTOBEORNOT#")...)
+		input.Append(byte(i))
+	}
+
+	return input
+}
+
+func TestDecompressedEqualsOriginal(t *testing.T) {
+	input := testInput()
+
+	for _, testCase := range codecs {
+		for _, threads := range []int{0, 1, 4} {
+			compressed, err := Compress(input, testCase.codec, 100, threads)
+			if err != nil {
+				t.Errorf("%s: Expected nil error, got %s", testCase.name, err)
+				continue
+			}
+
+			if !IsBlockFormat(compressed) {
+				t.Errorf("%s: Expected compressed data to be in the block format", testCase.name)
+			}
+
+			decompressed, err := Decompress(compressed, threads)
+			if err != nil {
+				t.Errorf("%s: Expected nil error, got %s", testCase.name, err)
+				continue
+			}
+
+			if !bytes.Equal(input.Slice(), decompressed.Slice()) {
+				t.Errorf("%s: Expected %v, got %v", testCase.name, input, decompressed)
+			}
+		}
+	}
+}
+
+func TestCompressWritesBlockIndex(t *testing.T) {
+	input := testInput()
+	compressed, _ := Compress(input, mustCodec("lzw", nil), 1000, 2)
+
+	algorithm, index, _, err := readHeader(compressed.Slice())
+	if err != nil {
+		t.Errorf("Expected nil error, got %s", err)
+	}
+
+	if algorithm != codec.LZW {
+		t.Errorf("Expected %d, got %d", codec.LZW, algorithm)
+	}
+
+	expectedBlocks := (input.Size() + 999) / 1000
+	if len(index) != expectedBlocks {
+		t.Errorf("Expected %d blocks, got %d", expectedBlocks, len(index))
+	}
+
+	if last := index[len(index)-1].uncompressedSize; last != uint32(input.Size()%1000) {
+		t.Errorf("Expected %d, got %d", input.Size()%1000, last)
+	}
+}
+
+func TestReadIndex(t *testing.T) {
+	input := testInput()
+	compressed, _ := Compress(input, mustCodec("huffman", nil), 1000, 2)
+
+	index, err := ReadIndex(compressed)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if index.Algorithm != codec.Huffman {
+		t.Errorf("Expected %s, got %s", codec.Huffman, index.Algorithm)
+	}
+
+	if index.UncompressedSize() != input.Size() {
+		t.Errorf("Expected %d, got %d", input.Size(), index.UncompressedSize())
+	}
+
+	compressedSize := headerSize + len(index.Blocks)*entrySize
+	for _, block := range index.Blocks {
+		compressedSize += block.CompressedSize
+	}
+
+	if compressedSize != compressed.Size() {
+		t.Errorf("Expected %d, got %d", compressed.Size(), compressedSize)
+	}
+}
+
+func TestEmptyInputRoundTrips(t *testing.T) {
+	for _, testCase := range codecs {
+		compressed, err := Compress(vector.New[byte](), testCase.codec, DefaultBlockSize, 4)
+		if err != nil {
+			t.Errorf("%s: Expected nil error, got %s", testCase.name, err)
+			continue
+		}
+
+		decompressed, err := Decompress(compressed, 4)
+		if err != nil {
+			t.Errorf("%s: Expected nil error, got %s", testCase.name, err)
+		}
+
+		if decompressed.Size() != 0 {
+			t.Errorf("%s: Expected an empty vector, got %v", testCase.name, decompressed)
+		}
+	}
+}
+
+func TestCompressReturnsErrorOnInvalidBlockSize(t *testing.T) {
+	if _, err := Compress(testInput(), mustCodec("huffman", nil), 0, 1); !errors.Is(err, ErrInvalidBlockSize) {
+		t.Errorf("Expected %s, got %v", ErrInvalidBlockSize, err)
+	}
+}
+
+func TestDecompressReturnsErrorOnInvalidHeader(t *testing.T) {
+	input := vector.New[byte]()
+	input.Append([]byte("GMPX\x01\x01\x00\x00\x00\x00")...)
+
+	if _, err := Decompress(input, 1); !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("Expected %s, got %v", ErrInvalidHeader, err)
+	}
+}
+
+func TestDecompressReturnsErrorOnCorruptIndex(t *testing.T) {
+	compressed, _ := Compress(testInput(), mustCodec("lzw", nil), 1000, 2)
+
+	testCases := []struct {
+		name   string
+		offset int
+		delta  byte
+	}{
+		{name: "block count", offset: 9, delta: 1},
+		{name: "uncompressed size", offset: headerSize + 3, delta: 1},
+		{name: "smaller uncompressed size", offset: headerSize + 3, delta: 255},
+		{name: "compressed size", offset: headerSize + 7, delta: 1},
+	}
+
+	for _, testCase := range testCases {
+		corrupted := vector.New[byte]()
+		corrupted.Append(compressed.Slice()...)
+		corrupted.MustSet(testCase.offset, corrupted.MustGet(testCase.offset)+testCase.delta)
+
+		if _, err := Decompress(corrupted, 2); !errors.Is(err, ErrCorruptIndex) {
+			t.Errorf("%s: Expected %s, got %v", testCase.name, ErrCorruptIndex, err)
+		}
+	}
+}
+
+func TestDecompressRejectsImplausibleIndex(t *testing.T) {
+	testCases := []struct {
+		name      string
+		algorithm codec.Algorithm
+	}{
+		{name: "Huffman", algorithm: codec.Huffman},
+		{name: "LZW", algorithm: codec.LZW},
+	}
+
+	const blocks = 20000
+
+	for _, testCase := range testCases {
+		bomb := vector.New[byte]()
+		bomb.Append(magic...)
+		bomb.Append(version, byte(testCase.algorithm))
+		bomb.Append(uint32Bytes(blocks)...)
+
+		for i := 0; i < blocks; i++ {
+			bomb.Append(uint32Bytes(0xFFFFFFFF)...)
+			bomb.Append(uint32Bytes(1)...)
+		}
+
+		bomb.Append(make([]byte, blocks)...)
+
+		if _, err := Decompress(bomb, 2); !errors.Is(err, ErrCorruptIndex) {
+			t.Errorf("%s: Expected %s, got %v", testCase.name, ErrCorruptIndex, err)
+		}
+	}
+}
+
+func TestDecompressWithLimitsChecksIndexFirst(t *testing.T) {
+	input := testInput()
+	compressed, _ := Compress(input, mustCodec("lzw", nil), 1000, 2)
+
+	_, err := DecompressWithLimits(compressed, 2, limit.Limits{MaxOutput: input.Size() - 1})
+	if !errors.Is(err, limit.ErrExceeded) {
+		t.Errorf("Expected %s, got %v", limit.ErrExceeded, err)
+	}
+
+	decompressed, err := DecompressWithLimits(compressed, 2, limit.Limits{MaxOutput: input.Size()})
+	if err != nil {
+		t.Errorf("Expected nil error, got %s", err)
+	}
+
+	if !bytes.Equal(input.Slice(), decompressed.Slice()) {
+		t.Error("Expected the decompressed data to equal the input")
+	}
+}
+
+func TestCompressContext(t *testing.T) {
+	input := testInput()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := CompressContext(cancelled, input, mustCodec("lzw", nil), 1000, 2, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %s, got %v", context.Canceled, err)
+	}
+
+	read := 0
+	_, err := CompressContext(context.Background(), input, mustCodec("lzw", nil), 1000, 2, func(in int, out int) {
+		read = in
+	})
+	if err != nil {
+		t.Errorf("Expected nil error, got %s", err)
+	}
+
+	if read != input.Size() {
+		t.Errorf("Expected %d, got %d", input.Size(), read)
+	}
+}
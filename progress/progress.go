@@ -0,0 +1,54 @@
+// Package progress reports the progress of long-running compression.
+package progress
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Interval is the number of input bytes processed between checks for
+// cancellation and progress reports.
+const Interval = 1 << 16
+
+// Func is called periodically with the number of bytes read from the input
+// and written into the output so far. A Func may be called from multiple
+// goroutines, but never concurrently.
+type Func func(in int, out int)
+
+// Report calls f, unless it is nil.
+func (f Func) Report(in int, out int) {
+	if f != nil {
+		f(in, out)
+	}
+}
+
+// Bar returns a progress bar of the given width, which is filled by the
+// fraction of done out of total.
+func Bar(done int, total int, width int) string {
+	filled := width
+	if total > 0 && done < total {
+		filled = int(int64(done) * int64(width) / int64(total))
+	}
+
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(" ", width-filled) + "]"
+}
+
+// FormatSize formats an amount of bytes with a binary unit.
+func FormatSize(size int) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value, suffix := float64(size)/unit, "KiB"
+	for _, next := range []string{"MiB", "GiB", "TiB"} {
+		if value < unit {
+			break
+		}
+
+		value, suffix = value/unit, next
+	}
+
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
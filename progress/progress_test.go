@@ -0,0 +1,56 @@
+package progress
+
+import "testing"
+
+func TestBar(t *testing.T) {
+	testCases := []struct {
+		done     int
+		total    int
+		expected string
+	}{
+		{done: 0, total: 100, expected: "[          ]"},
+		{done: 55, total: 100, expected: "[#####     ]"},
+		{done: 100, total: 100, expected: "[##########]"},
+		{done: 200, total: 100, expected: "[##########]"},
+		{done: 0, total: 0, expected: "[##########]"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := Bar(testCase.done, testCase.total, 10); actual != testCase.expected {
+			t.Errorf("%d/%d: Expected %q, got %q", testCase.done, testCase.total, testCase.expected, actual)
+		}
+	}
+}
+
+func TestReportIgnoresNilFunc(t *testing.T) {
+	var f Func
+	f.Report(1, 2)
+
+	calls := 0
+	f = func(in int, out int) { calls++ }
+	f.Report(1, 2)
+
+	if calls != 1 {
+		t.Errorf("Expected %d, got %d", 1, calls)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	testCases := []struct {
+		size     int
+		expected string
+	}{
+		{size: 0, expected: "0 B"},
+		{size: 1023, expected: "1023 B"},
+		{size: 1024, expected: "1.0 KiB"},
+		{size: 1536, expected: "1.5 KiB"},
+		{size: 5 << 20, expected: "5.0 MiB"},
+		{size: 3 << 30, expected: "3.0 GiB"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := FormatSize(testCase.size); actual != testCase.expected {
+			t.Errorf("%d: Expected %q, got %q", testCase.size, testCase.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mjjs/gompressor/progress"
+)
+
+// progressInterval is the minimum time between redrawing a progress bar.
+const progressInterval = 100 * time.Millisecond
+
+// progressBar draws the progress of processing a file on a single line of a
+// terminal.
+type progressBar struct {
+	w     io.Writer
+	name  string
+	total int
+	drawn time.Time
+}
+
+// newProgressBar returns a progress bar for the file whose size is total
+// bytes. A total of zero or less means the size is unknown, in which case
+// only the amount of bytes is shown.
+func newProgressBar(w io.Writer, name string, total int) *progressBar {
+	return &progressBar{w: w, name: name, total: total}
+}
+
+// update redraws the bar with the bytes read and written so far, unless it
+// has been drawn within the progress interval.
+func (b *progressBar) update(in int, out int) {
+	now := time.Now()
+	if now.Sub(b.drawn) < progressInterval {
+		return
+	}
+
+	b.drawn = now
+
+	if b.total <= 0 {
+		fmt.Fprintf(b.w, "\r%s: %s -> %s", b.name, progress.FormatSize(in), progress.FormatSize(out))
+		return
+	}
+
+	fmt.Fprintf(b.w, "\r%s: %s %3d%% %s -> %s", b.name, progress.Bar(in, b.total, 30),
+		int(int64(in)*100/int64(b.total)), progress.FormatSize(in), progress.FormatSize(out))
+}
+
+// clear erases the bar, so that the result of the file can be printed on
+// its line.
+func (b *progressBar) clear() {
+	if !b.drawn.IsZero() {
+		fmt.Fprint(b.w, "\r\033[K")
+	}
+}
+
+// progressReader reads from r, reporting the amount of bytes read so far and
+// stopping with the error of the context when it is done.
+type progressReader struct {
+	ctx    context.Context
+	r      io.Reader
+	read   int
+	report func(read int)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := r.r.Read(p)
+	r.read += n
+	r.report(r.read)
+
+	return n, err
+}
+
+// showProgress returns the function reporting the progress of processing the
+// file of the given size, and the function clearing the progress bar. When
+// show is false, no progress is shown.
+func showProgress(filename string, total int, show bool) (progress.Func, func()) {
+	if !show {
+		return nil, func() {}
+	}
+
+	bar := newProgressBar(os.Stderr, displayName(filename, true), total)
+
+	return bar.update, bar.clear
+}
@@ -0,0 +1,427 @@
+// Package seekable implements a compressed format which supports reading any
+// byte range without decompressing the whole data.
+//
+// The data is compressed in independent blocks of a fixed size. The blocks
+// are followed by an index mapping the uncompressed offset of each block to
+// the offset of the compressed block, and a fixed size footer:
+//
+//	header   "GMPS", version byte, algorithm byte, block size uint32
+//	blocks   the compressed blocks
+//	index    blocks * (uncompressed offset uint64, compressed offset uint64)
+//	footer   uncompressed size uint64, blocks uint32, index offset uint64, "GMPS"
+//
+// All integers are stored in big-endian byte order.
+package seekable
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/limit"
+)
+
+// DefaultBlockSize is the default amount of uncompressed bytes in a block.
+const DefaultBlockSize int = 256 * 1024
+
+// MagicSize is the amount of bytes IsSeekable needs to recognize the format.
+const MagicSize int = 4
+
+const (
+	version    byte = 1
+	headerSize int  = 10
+	entrySize  int  = 16
+	footerSize int  = 24
+)
+
+var magic = []byte("GMPS")
+
+// ErrInvalidFormat is returned when the data is not in the seekable format.
+var ErrInvalidFormat = errors.New("invalid seekable format")
+
+// ErrCorruptIndex is returned when the block index does not match the data.
+var ErrCorruptIndex = errors.New("corrupt block index")
+
+// ErrInvalidBlockSize is returned when the block size is not positive or does
+// not fit into the header.
+var ErrInvalidBlockSize = errors.New("invalid block size")
+
+// ErrInvalidOffset is returned when seeking or reading at a negative offset.
+var ErrInvalidOffset = errors.New("invalid offset")
+
+// IsSeekable reports whether data starts with the header of the format.
+func IsSeekable(data *vector.Vector[byte]) bool {
+	return bytes.HasPrefix(data.Slice(), magic)
+}
+
+type indexEntry struct {
+	uncompressedOffset int64
+	compressedOffset   int64
+}
+
+// Writer compresses the data written into it in blocks. Close must be called
+// to compress the last block and to write the index.
+type Writer struct {
+	w         io.Writer
+	codec     codec.Codec
+	blockSize int
+	block     []byte
+	index     []indexEntry
+	written   int64
+	size      int64
+	err       error
+}
+
+// NewWriter writes the header into w and returns a Writer which compresses
+// blocks of blockSize bytes with the codec.
+func NewWriter(w io.Writer, c codec.Codec, blockSize int) (*Writer, error) {
+	if blockSize <= 0 || int64(blockSize) > math.MaxUint32 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidBlockSize, blockSize)
+	}
+
+	writer := &Writer{
+		w:         w,
+		codec:     c,
+		blockSize: blockSize,
+		block:     make([]byte, 0, blockSize),
+	}
+
+	header := append(append([]byte{}, magic...), version, byte(c.Algorithm()), 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(header[6:], uint32(blockSize))
+	writer.write(header)
+
+	return writer, writer.err
+}
+
+// Write buffers p and compresses every full block.
+func (w *Writer) Write(p []byte) (int, error) {
+	written := 0
+
+	for len(p) > 0 && w.err == nil {
+		n := copy(w.block[len(w.block):cap(w.block)], p)
+		w.block = w.block[:len(w.block)+n]
+		p = p[n:]
+		written += n
+
+		if len(w.block) == w.blockSize {
+			w.flushBlock()
+		}
+	}
+
+	return written, w.err
+}
+
+// Close compresses the last block and writes the index and the footer. Close
+// does not close the underlying writer.
+func (w *Writer) Close() error {
+	if len(w.block) > 0 {
+		w.flushBlock()
+	}
+
+	indexOffset := w.written
+	entry := make([]byte, entrySize)
+
+	for _, e := range w.index {
+		binary.BigEndian.PutUint64(entry[0:8], uint64(e.uncompressedOffset))
+		binary.BigEndian.PutUint64(entry[8:16], uint64(e.compressedOffset))
+		w.write(entry)
+	}
+
+	footer := make([]byte, footerSize)
+	binary.BigEndian.PutUint64(footer[0:8], uint64(w.size))
+	binary.BigEndian.PutUint32(footer[8:12], uint32(len(w.index)))
+	binary.BigEndian.PutUint64(footer[12:20], uint64(indexOffset))
+	copy(footer[20:], magic)
+	w.write(footer)
+
+	return w.err
+}
+
+// BytesWritten returns the amount of compressed bytes written so far.
+func (w *Writer) BytesWritten() int64 {
+	return w.written
+}
+
+func (w *Writer) flushBlock() {
+	block := vector.New[byte](0, uint(len(w.block)))
+	block.Append(w.block...)
+
+	compressed, err := w.codec.Compress(block)
+	if err != nil {
+		w.err = err
+		return
+	}
+
+	w.index = append(w.index, indexEntry{uncompressedOffset: w.size, compressedOffset: w.written})
+	w.size += int64(len(w.block))
+	w.block = w.block[:0]
+
+	w.write(compressed.Slice())
+}
+
+func (w *Writer) write(p []byte) {
+	if w.err != nil {
+		return
+	}
+
+	n, err := w.w.Write(p)
+	w.written += int64(n)
+	w.err = err
+}
+
+// Reader reads the uncompressed data of the seekable format. Only the blocks
+// containing the requested bytes are read and decompressed.
+type Reader struct {
+	r           io.ReaderAt
+	codec       codec.Codec
+	index       []indexEntry
+	size        int64
+	indexOffset int64
+	offset      int64
+
+	mu          sync.Mutex
+	cachedBlock int
+	cached      []byte
+}
+
+// NewReader reads the index of the compressed data of the given size from r.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	if size < int64(headerSize+footerSize) {
+		return nil, ErrInvalidFormat
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+
+	footer := make([]byte, footerSize)
+	if _, err := r.ReadAt(footer, size-int64(footerSize)); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(header[:len(magic)], magic) || !bytes.Equal(footer[20:], magic) {
+		return nil, ErrInvalidFormat
+	}
+
+	if header[4] != version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidFormat, header[4])
+	}
+
+	c, err := codec.ForAlgorithm(codec.Algorithm(header[5]))
+	if err != nil {
+		return nil, err
+	}
+
+	uncompressedSize := int64(binary.BigEndian.Uint64(footer[0:8]))
+	blocks := int64(binary.BigEndian.Uint32(footer[8:12]))
+	indexOffset := int64(binary.BigEndian.Uint64(footer[12:20]))
+
+	if indexOffset < int64(headerSize) || indexOffset+blocks*int64(entrySize) != size-int64(footerSize) {
+		return nil, fmt.Errorf("%w: index does not fit into the data", ErrCorruptIndex)
+	}
+
+	indexBytes := make([]byte, blocks*int64(entrySize))
+	if _, err := r.ReadAt(indexBytes, indexOffset); err != nil {
+		return nil, err
+	}
+
+	index := make([]indexEntry, blocks)
+	for i := range index {
+		entry := indexBytes[i*entrySize:]
+		index[i] = indexEntry{
+			uncompressedOffset: int64(binary.BigEndian.Uint64(entry[0:8])),
+			compressedOffset:   int64(binary.BigEndian.Uint64(entry[8:16])),
+		}
+	}
+
+	reader := &Reader{
+		r:           r,
+		codec:       c,
+		index:       index,
+		size:        uncompressedSize,
+		indexOffset: indexOffset,
+		cachedBlock: -1,
+	}
+
+	if err := reader.validateIndex(); err != nil {
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+// Size returns the size of the uncompressed data.
+func (r *Reader) Size() int64 {
+	return r.size
+}
+
+// Algorithm returns the algorithm the blocks are compressed with.
+func (r *Reader) Algorithm() codec.Algorithm {
+	return r.codec.Algorithm()
+}
+
+// Blocks returns the number of compressed blocks.
+func (r *Reader) Blocks() int {
+	return len(r.index)
+}
+
+// ReadAt reads len(p) uncompressed bytes starting at off. ReadAt can be called
+// concurrently.
+func (r *Reader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidOffset, off)
+	}
+
+	n := 0
+
+	for n < len(p) && off < r.size {
+		i := r.blockAt(off)
+
+		block, err := r.block(i)
+		if err != nil {
+			return n, err
+		}
+
+		copied := copy(p[n:], block[off-r.index[i].uncompressedOffset:])
+		n += copied
+		off += int64(copied)
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// Read reads uncompressed bytes starting at the current offset.
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > r.size-r.offset {
+		p = p[:r.size-r.offset]
+	}
+
+	n, err := r.ReadAt(p, r.offset)
+	r.offset += int64(n)
+
+	return n, err
+}
+
+// Seek sets the offset of the next Read in the uncompressed data.
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+
+	if offset < 0 {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidOffset, offset)
+	}
+
+	r.offset = offset
+
+	return offset, nil
+}
+
+func (r *Reader) validateIndex() error {
+	var previous indexEntry
+
+	for i, entry := range r.index {
+		if i == 0 && (entry.uncompressedOffset != 0 || entry.compressedOffset != int64(headerSize)) {
+			return fmt.Errorf("%w: first block does not start the data", ErrCorruptIndex)
+		}
+
+		if i > 0 && (entry.uncompressedOffset <= previous.uncompressedOffset ||
+			entry.compressedOffset < previous.compressedOffset) {
+			return fmt.Errorf("%w: block %d is out of order", ErrCorruptIndex, i)
+		}
+
+		previous = entry
+	}
+
+	if len(r.index) == 0 && r.size != 0 {
+		return fmt.Errorf("%w: no blocks for %d bytes", ErrCorruptIndex, r.size)
+	}
+
+	if len(r.index) > 0 && (previous.uncompressedOffset >= r.size || previous.compressedOffset > r.indexOffset) {
+		return fmt.Errorf("%w: last block is out of bounds", ErrCorruptIndex)
+	}
+
+	return nil
+}
+
+// blockAt returns the index of the block containing the uncompressed offset.
+func (r *Reader) blockAt(off int64) int {
+	return sort.Search(len(r.index), func(i int) bool {
+		return r.index[i].uncompressedOffset > off
+	}) - 1
+}
+
+// bounds returns the uncompressed size and the compressed range of block i.
+func (r *Reader) bounds(i int) (int64, int64, int64) {
+	uncompressedEnd, compressedEnd := r.size, r.indexOffset
+
+	if i+1 < len(r.index) {
+		uncompressedEnd = r.index[i+1].uncompressedOffset
+		compressedEnd = r.index[i+1].compressedOffset
+	}
+
+	return uncompressedEnd - r.index[i].uncompressedOffset, r.index[i].compressedOffset, compressedEnd
+}
+
+// block returns the decompressed block i. The last decompressed block is
+// cached, so that consecutive small reads decompress each block only once.
+func (r *Reader) block(i int) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cachedBlock == i {
+		return r.cached, nil
+	}
+
+	size, start, end := r.bounds(i)
+
+	compressed := make([]byte, end-start)
+	if _, err := r.r.ReadAt(compressed, start); err != nil {
+		return nil, err
+	}
+
+	compressedVector := vector.New[byte](0, uint(len(compressed)))
+	compressedVector.Append(compressed...)
+
+	// A block can not expand beyond the size stored in the index.
+	decompressed, err := codec.DecompressWithLimits(r.codec, compressedVector, limit.Limits{MaxOutput: int(size)})
+	if errors.Is(err, limit.ErrExceeded) {
+		return nil, fmt.Errorf("%w: block %d decompressed into more than %d bytes", ErrCorruptIndex, i, size)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", i, err)
+	}
+
+	if int64(decompressed.Size()) != size {
+		return nil, fmt.Errorf("%w: block %d decompressed into %d bytes, expected %d",
+			ErrCorruptIndex, i, decompressed.Size(), size)
+	}
+
+	r.cachedBlock = i
+	r.cached = decompressed.Slice()
+
+	return r.cached, nil
+}
@@ -0,0 +1,217 @@
+package seekable
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/mjjs/gompressor/codec"
+)
+
+func testData() []byte {
+	data := new(bytes.Buffer)
+	for i := 0; data.Len() < 10000; i++ {
+		data.WriteString("TOBEORNOTTOBEORTOBEORNOT#")
+		data.WriteByte(byte(i))
+	}
+
+	return data.Bytes()
+}
+
+func compress(t *testing.T, data []byte, algorithm codec.Algorithm, blockSize int) []byte {
+	c, err := codec.ForAlgorithm(algorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+
+	writer, err := NewWriter(buf, c, blockSize)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	// Write in uneven chunks to exercise the block buffering.
+	for len(data) > 0 {
+		n := 333
+		if n > len(data) {
+			n = len(data)
+		}
+
+		if _, err := writer.Write(data[:n]); err != nil {
+			t.Fatalf("Expected nil error, got %s", err)
+		}
+
+		data = data[n:]
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if writer.BytesWritten() != int64(buf.Len()) {
+		t.Errorf("Expected %d bytes written, got %d", buf.Len(), writer.BytesWritten())
+	}
+
+	return buf.Bytes()
+}
+
+// countingReaderAt counts the bytes read from the underlying data.
+type countingReaderAt struct {
+	r    *bytes.Reader
+	read int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.read += n
+	return n, err
+}
+
+func TestReadAllEqualsOriginal(t *testing.T) {
+	data := testData()
+
+	for _, algorithm := range []codec.Algorithm{codec.Huffman, codec.LZW} {
+		for _, input := range [][]byte{data, data[:1000], {}} {
+			compressed := compress(t, input, algorithm, 1000)
+
+			reader, err := NewReader(bytes.NewReader(compressed), int64(len(compressed)))
+			if err != nil {
+				t.Fatalf("%s: Expected nil error, got %s", algorithm, err)
+			}
+
+			if reader.Size() != int64(len(input)) {
+				t.Errorf("%s: Expected size %d, got %d", algorithm, len(input), reader.Size())
+			}
+
+			actual, err := io.ReadAll(reader)
+			if err != nil {
+				t.Errorf("%s: Expected nil error, got %s", algorithm, err)
+			}
+
+			if !bytes.Equal(input, actual) {
+				t.Errorf("%s: Decompressed data differs from the original", algorithm)
+			}
+		}
+	}
+}
+
+func TestReadAtReadsOnlyNeededBlocks(t *testing.T) {
+	data := testData()
+	compressed := compress(t, data, codec.LZW, 1000)
+
+	source := &countingReaderAt{r: bytes.NewReader(compressed)}
+	reader, err := NewReader(source, int64(len(compressed)))
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	indexRead := source.read
+
+	testCases := []struct {
+		offset int64
+		length int
+	}{
+		{offset: 0, length: 10},
+		{offset: 995, length: 10},
+		{offset: 4321, length: 2000},
+		{offset: int64(len(data)) - 5, length: 5},
+	}
+
+	for _, testCase := range testCases {
+		actual := make([]byte, testCase.length)
+
+		n, err := reader.ReadAt(actual, testCase.offset)
+		if err != nil || n != testCase.length {
+			t.Errorf("Expected %d bytes and nil error, got %d and %v", testCase.length, n, err)
+		}
+
+		expected := data[testCase.offset : testCase.offset+int64(testCase.length)]
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+	}
+
+	if read := source.read - indexRead; read >= len(compressed)-indexRead {
+		t.Errorf("Expected less than the whole data to be read, read %d of %d bytes", read, len(compressed))
+	}
+}
+
+func TestReadAtPastEndReturnsEOF(t *testing.T) {
+	data := testData()
+	compressed := compress(t, data, codec.Huffman, 1000)
+	reader, _ := NewReader(bytes.NewReader(compressed), int64(len(compressed)))
+
+	buf := make([]byte, 10)
+
+	if n, err := reader.ReadAt(buf, int64(len(data))-4); n != 4 || !errors.Is(err, io.EOF) {
+		t.Errorf("Expected %d bytes and %s, got %d and %v", 4, io.EOF, n, err)
+	}
+
+	if _, err := reader.ReadAt(buf, -1); !errors.Is(err, ErrInvalidOffset) {
+		t.Errorf("Expected %s, got %v", ErrInvalidOffset, err)
+	}
+}
+
+func TestSeek(t *testing.T) {
+	data := testData()
+	compressed := compress(t, data, codec.LZW, 1000)
+	reader, _ := NewReader(bytes.NewReader(compressed), int64(len(compressed)))
+
+	testCases := []struct {
+		offset   int64
+		whence   int
+		expected int64
+	}{
+		{offset: 2500, whence: io.SeekStart, expected: 2500},
+		{offset: -500, whence: io.SeekCurrent, expected: 2050},
+		{offset: -100, whence: io.SeekEnd, expected: int64(len(data)) - 100},
+	}
+
+	for _, testCase := range testCases {
+		position, err := reader.Seek(testCase.offset, testCase.whence)
+		if err != nil || position != testCase.expected {
+			t.Errorf("Expected %d and nil error, got %d and %v", testCase.expected, position, err)
+		}
+
+		actual := make([]byte, 50)
+		io.ReadFull(reader, actual)
+
+		if expected := data[position : position+50]; !bytes.Equal(expected, actual) {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+	}
+
+	if _, err := reader.Seek(-1, io.SeekStart); !errors.Is(err, ErrInvalidOffset) {
+		t.Errorf("Expected %s, got %v", ErrInvalidOffset, err)
+	}
+}
+
+func TestNewReaderRejectsInvalidData(t *testing.T) {
+	compressed := compress(t, testData(), codec.LZW, 1000)
+
+	corruptFooter := append([]byte{}, compressed...)
+	corruptFooter[len(corruptFooter)-5]++
+
+	corruptIndex := append([]byte{}, compressed...)
+	corruptIndex[len(corruptIndex)-footerSize-entrySize+8]++
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected error
+	}{
+		{name: "too short", data: compressed[:20], expected: ErrInvalidFormat},
+		{name: "truncated", data: compressed[:len(compressed)-1], expected: ErrInvalidFormat},
+		{name: "index offset", data: corruptFooter, expected: ErrCorruptIndex},
+		{name: "block offset", data: corruptIndex, expected: ErrCorruptIndex},
+	}
+
+	for _, testCase := range testCases {
+		_, err := NewReader(bytes.NewReader(testCase.data), int64(len(testCase.data)))
+		if !errors.Is(err, testCase.expected) {
+			t.Errorf("%s: Expected %s, got %v", testCase.name, testCase.expected, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/fileio"
+)
+
+// stdio is the file name which stands for the standard input when reading and
+// the standard output when writing.
+const stdio = "-"
+
+// output is a file or the standard output which data is written into. A file
+// is replaced only when the output is committed, and closing an output which
+// has not been committed discards the written data.
+type output interface {
+	io.Writer
+	Commit() error
+	Close() error
+}
+
+// stdout is the standard output as an output, which is never closed.
+type stdout struct {
+	io.Writer
+}
+
+func (stdout) Commit() error {
+	return nil
+}
+
+func (stdout) Close() error {
+	return nil
+}
+
+// inputFiles returns the files given as arguments, or the standard input when
+// no files are given.
+func inputFiles(args []string) []string {
+	if len(args) == 0 {
+		return []string{stdio}
+	}
+
+	return args
+}
+
+// openInput opens the file, or the standard input for stdio.
+func openInput(filename string) (io.ReadCloser, error) {
+	if filename == stdio {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(filename)
+}
+
+// createOutput creates the file, or returns the standard output for stdio.
+func createOutput(filename string) (output, error) {
+	if filename == stdio {
+		return stdout{os.Stdout}, nil
+	}
+
+	return fileio.CreateAtomic(filename)
+}
+
+// readInput reads the whole file, or the standard input for stdio.
+func readInput(filename string) (*vector.Vector[byte], error) {
+	if filename == stdio {
+		return fileio.Read(os.Stdin)
+	}
+
+	return fileio.ReadFile(filename)
+}
+
+// loadInput returns the contents of the file, or the standard input for stdio.
+// The file is mapped into memory instead of being read when mmap is true. The
+// returned function releases the contents, which must not be used after it.
+func loadInput(filename string, mmap bool) (*vector.Vector[byte], func() error, error) {
+	if !mmap || filename == stdio {
+		bytes, err := readInput(filename)
+		return bytes, func() error { return nil }, err
+	}
+
+	file, err := fileio.MapFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file.Vector(), file.Close, nil
+}
+
+// writeOutput writes the bytes into the file, or the standard output for
+// stdio, and returns the amount of bytes written. The file is written through
+// a memory mapping when mmap is true.
+func writeOutput(bytes *vector.Vector[byte], filename string, mmap bool) (int, error) {
+	var err error
+
+	switch {
+	case filename == stdio:
+		err = fileio.Write(os.Stdout, bytes)
+	case mmap:
+		err = fileio.WriteFileMapped(bytes, filename)
+	default:
+		err = fileio.WriteFile(bytes, filename)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("could not write data: %w", err)
+	}
+
+	return bytes.Size(), nil
+}
+
+// isTerminal reports whether the file is a terminal.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// displayName returns the name of the file shown to the user.
+func displayName(filename string, input bool) string {
+	switch {
+	case filename != stdio:
+		return filename
+	case input:
+		return "stdin"
+	default:
+		return "stdout"
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mjjs/gompressor/ui"
+)
+
+func runTUI(name string, args []string) int {
+	flags := newFlagSet(name)
+	dirFlag := flags.String("dir", ".", "start the file tree from the `directory`")
+	flags.Parse(args)
+
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+
+	info, err := os.Stat(*dirFlag)
+	if err != nil {
+		return fail(err)
+	}
+
+	if !info.IsDir() {
+		return fail(fmt.Errorf("%s is not a directory", *dirFlag))
+	}
+
+	ui.New().SetDirectory(*dirFlag).Run()
+
+	return 0
+}
@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mjjs/gompressor/batch"
+	"github.com/mjjs/gompressor/codec"
+	"github.com/rivo/tview"
+)
+
+// Statuses of the files in a batch.
+const (
+	statusWaiting   = "waiting"
+	statusRunning   = "running"
+	statusDone      = "done"
+	statusFailed    = "failed"
+	statusCancelled = "cancelled"
+)
+
+// batchRow is the status of a file in a batch.
+type batchRow struct {
+	status string
+	text   string
+}
+
+// batchView shows the status of each file of a batch running in the
+// background, and a summary when the batch is done.
+type batchView struct {
+	view  *tview.TextView
+	start time.Time
+
+	mu        sync.Mutex
+	rows      []batchRow
+	cancelled bool
+	summary   string
+}
+
+func (v *batchView) draw() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var text strings.Builder
+
+	for _, row := range v.rows {
+		fmt.Fprintf(&text, "%-10s %s\n", row.status, row.text)
+	}
+
+	fmt.Fprintf(&text, "\nElapsed %s\n", formatElapsed(time.Since(v.start)))
+
+	switch {
+	case v.summary != "":
+		fmt.Fprintf(&text, "%s\n\nPress Enter to return to main menu", v.summary)
+	case v.cancelled:
+		text.WriteString("Cancelling...")
+	default:
+		text.WriteString("Press Escape to cancel")
+	}
+
+	v.view.SetText(text.String())
+}
+
+func (v *batchView) setRow(i int, row batchRow) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.rows[i] = row
+}
+
+// runBatch processes the files one after another in the background, showing
+// the status of each file. The failures are paths which could not be expanded
+// into files, and are shown as failed. Pressing Escape cancels the files which
+// have not been processed yet.
+func (u UI) runBatch(title string, files []string, failures []batch.Result, action action, registration codec.Registration) {
+	if len(files) == 0 && len(failures) == 0 {
+		u.messageView("None of the marked files can be processed")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := *u.settings
+
+	v := &batchView{view: tview.NewTextView(), start: time.Now()}
+
+	for _, failure := range failures {
+		v.rows = append(v.rows, batchRow{status: statusFailed, text: failure.String()})
+	}
+
+	index := map[string]int{}
+
+	for _, file := range files {
+		index[file] = len(v.rows)
+		v.rows = append(v.rows, batchRow{status: statusWaiting, text: file})
+	}
+
+	finished := false
+
+	v.view.SetDoneFunc(func(key tcell.Key) {
+		if finished {
+			u.application.SetRoot(u.getStartMenu(), true)
+			return
+		}
+
+		if key == tcell.KeyEscape {
+			v.mu.Lock()
+			v.cancelled = true
+			v.mu.Unlock()
+
+			cancel()
+			v.draw()
+		}
+	})
+	v.view.SetBorder(true).SetTitle(fmt.Sprintf(" %s %d files ", title, len(files)+len(failures)))
+	v.draw()
+
+	u.application.SetRoot(v.view, true)
+
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				u.application.QueueUpdateDraw(v.draw)
+			}
+		}
+	}()
+
+	go func() {
+		// The files are processed one at a time, as files written into the
+		// same output directory may resolve to the same output file.
+		results := batch.Run(files, 1, func(file string) batch.Result {
+			i := index[file]
+
+			if err := ctx.Err(); err != nil {
+				v.setRow(i, batchRow{status: statusCancelled, text: file})
+				return batch.Result{Input: file, Err: err}
+			}
+
+			v.setRow(i, batchRow{status: statusRunning, text: file})
+
+			var result batch.Result
+			if action == actionDecompress {
+				result = decompressFile(ctx, file, registration, s)
+			} else {
+				result = compressFile(ctx, file, registration, s, nil)
+			}
+
+			switch {
+			case errors.Is(result.Err, context.Canceled):
+				v.setRow(i, batchRow{status: statusCancelled, text: file})
+			case result.Err != nil:
+				v.setRow(i, batchRow{status: statusFailed, text: result.String()})
+			default:
+				v.setRow(i, batchRow{status: statusDone, text: result.String()})
+			}
+
+			return result
+		})
+
+		close(done)
+		cancel()
+
+		summary := batch.Summarize(append(failures, results...)).String()
+
+		u.application.QueueUpdateDraw(func() {
+			finished = true
+
+			v.mu.Lock()
+			v.summary = summary
+			v.mu.Unlock()
+
+			v.draw()
+		})
+	}()
+}
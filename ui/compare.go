@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mjjs/gompressor/benchmark"
+	"github.com/mjjs/gompressor/fileio"
+	"github.com/mjjs/gompressor/progress"
+)
+
+// compareJob compresses and decompresses the file in memory with each of the
+// candidates, and returns a table of the results. The progress is reported as
+// the amount of input compressed by all the candidates so far.
+func compareJob(filename string, candidates []benchmark.Candidate) jobFunc {
+	return func(ctx context.Context, report progress.Func) (string, error) {
+		data, err := fileio.ReadFile(filename)
+		if err != nil {
+			return "", err
+		}
+
+		var table strings.Builder
+
+		w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Codec\tCompressed\tRatio\tCompress\tDecompress\tVerified")
+
+		for i, candidate := range candidates {
+			done := i * data.Size()
+
+			result, err := benchmark.Run(ctx, data, candidate.Codec, func(in int, out int) {
+				report.Report(done+in, 0)
+			})
+			if errors.Is(err, context.Canceled) {
+				return "", err
+			}
+			if err != nil {
+				fmt.Fprintf(w, "%s\t-\t-\t-\t-\tfailed: %s\n", candidate.Name, err)
+				continue
+			}
+
+			verified := "yes"
+			if !result.OK {
+				verified = "NO"
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%.2f%%\t%s\t%s\t%s\n",
+				candidate.Name, progress.FormatSize(result.CompressedSize), result.Ratio(),
+				formatDuration(result.CompressTime), formatDuration(result.DecompressTime), verified)
+		}
+
+		w.Flush()
+
+		return fmt.Sprintf("Compared the algorithms on %s (%s)\n\n%s",
+			filename, progress.FormatSize(data.Size()), table.String()), nil
+	}
+}
+
+// formatDuration formats a duration in milliseconds.
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%d ms", d.Milliseconds())
+}
@@ -0,0 +1,423 @@
+package ui
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mjjs/gompressor/batch"
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/progress"
+	"github.com/rivo/tview"
+)
+
+// timeLayout is the layout of the modification times in the file tree.
+const timeLayout = "2006-01-02 15:04"
+
+// fileEntry is the reference of a node in the file tree.
+type fileEntry struct {
+	path string
+	dir  bool
+	// parent is set for the node navigating to the parent directory.
+	parent bool
+}
+
+// fileTree shows the files under a directory. Files and whole directories can
+// be marked, and the marked files are processed as a batch.
+type fileTree struct {
+	u            UI
+	action       action
+	registration codec.Registration
+	root         string
+
+	tree   *tview.TreeView
+	filter *tview.InputField
+	path   *tview.InputField
+	help   *tview.TextView
+	layout *tview.Flex
+
+	showHidden bool
+	expanded   map[string]bool
+	marked     map[string]bool
+}
+
+func (u UI) fileSelect(action action, registration codec.Registration) func() {
+	return func() {
+		t := newFileTree(u, action, registration, *u.directory)
+		u.application.SetRoot(t.layout, true).SetFocus(t.tree)
+	}
+}
+
+func newFileTree(u UI, action action, registration codec.Registration, root string) *fileTree {
+	t := &fileTree{
+		u:            u,
+		action:       action,
+		registration: registration,
+		root:         root,
+		tree:         tview.NewTreeView(),
+		filter:       tview.NewInputField().SetLabel("Filter: "),
+		path:         tview.NewInputField().SetLabel("Path:   "),
+		help:         tview.NewTextView().SetDynamicColors(true),
+		expanded:     map[string]bool{root: true},
+		marked:       map[string]bool{},
+	}
+
+	t.tree.SetSelectedFunc(t.selectNode)
+	t.tree.SetInputCapture(t.handleKey)
+
+	t.filter.SetChangedFunc(func(string) {
+		t.rebuild()
+	})
+	t.filter.SetDoneFunc(func(tcell.Key) {
+		u.application.SetFocus(t.tree)
+	})
+
+	t.path.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			t.changeRoot(t.path.GetText())
+		} else {
+			t.path.SetText(t.root)
+		}
+
+		u.application.SetFocus(t.tree)
+	})
+
+	t.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(t.tree, 0, 1, true).
+		AddItem(t.filter, 1, 0, false).
+		AddItem(t.path, 1, 0, false).
+		AddItem(t.help, 1, 0, false)
+
+	t.path.SetText(root)
+	t.rebuild()
+
+	return t
+}
+
+func (t *fileTree) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	switch {
+	case event.Key() == tcell.KeyEscape:
+		t.u.application.SetRoot(t.u.getStartMenu(), true)
+	case event.Rune() == ' ' && t.batchable():
+		if entry, ok := t.currentEntry(); ok && !entry.parent {
+			t.marked[entry.path] = !t.marked[entry.path]
+			t.rebuild()
+		}
+	case event.Rune() == 'r' && t.batchable():
+		if len(t.markedPaths()) > 0 {
+			t.runBatch()
+		}
+	case event.Rune() == '.':
+		t.showHidden = !t.showHidden
+		t.rebuild()
+	case event.Rune() == '/':
+		t.u.application.SetFocus(t.filter)
+	case event.Rune() == 'p':
+		t.u.application.SetFocus(t.path)
+	case event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2:
+		if parent, ok := parentDirectory(t.root); ok {
+			t.changeRoot(parent)
+		}
+	default:
+		return event
+	}
+
+	return nil
+}
+
+// selectNode opens and closes directories. Selecting a file processes the
+// marked files, or the selected file if no files are marked.
+func (t *fileTree) selectNode(node *tview.TreeNode) {
+	entry, ok := node.GetReference().(fileEntry)
+	if !ok {
+		return
+	}
+
+	if entry.parent {
+		t.changeRoot(entry.path)
+		return
+	}
+
+	if entry.dir {
+		t.expanded[entry.path] = !t.expanded[entry.path]
+		t.rebuild()
+
+		return
+	}
+
+	if t.batchable() && len(t.markedPaths()) > 0 {
+		t.runBatch()
+		return
+	}
+
+	t.u.selectFile(entry.path, t.action, t.registration)()
+}
+
+func (t *fileTree) currentEntry() (fileEntry, bool) {
+	node := t.tree.GetCurrentNode()
+	if node == nil {
+		return fileEntry{}, false
+	}
+
+	entry, ok := node.GetReference().(fileEntry)
+
+	return entry, ok
+}
+
+// changeRoot shows the tree of the directory instead. The start directory of
+// the following file trees is changed as well. Marked files stay marked.
+func (t *fileTree) changeRoot(dir string) {
+	dir = filepath.Clean(dir)
+
+	info, err := os.Stat(dir)
+	if err == nil && !info.IsDir() {
+		err = fmt.Errorf("%s is not a directory", dir)
+	}
+
+	if err != nil {
+		t.path.SetText(t.root)
+		t.help.SetText("[red]" + tview.Escape(err.Error()))
+
+		return
+	}
+
+	t.root = dir
+	t.expanded = map[string]bool{dir: true}
+	*t.u.directory = dir
+
+	t.path.SetText(dir)
+	t.tree.SetCurrentNode(nil)
+	t.rebuild()
+}
+
+// rebuild recreates the nodes of the tree, keeping the current node selected.
+func (t *fileTree) rebuild() {
+	current, _ := t.currentEntry()
+
+	name := t.root
+	if absolute, err := filepath.Abs(t.root); err == nil {
+		name = absolute
+	}
+
+	root := tview.NewTreeNode(t.label(t.root, name)).
+		SetColor(t.color(t.root, tcell.ColorRed)).
+		SetReference(fileEntry{path: t.root, dir: true})
+
+	if parent, ok := parentDirectory(t.root); ok {
+		root.AddChild(tview.NewTreeNode("..").
+			SetColor(tcell.ColorBlue).
+			SetReference(fileEntry{path: parent, dir: true, parent: true}))
+	}
+
+	t.addChildren(root, t.root)
+	t.tree.SetRoot(root).SetCurrentNode(root)
+
+	root.Walk(func(node *tview.TreeNode, parent *tview.TreeNode) bool {
+		if entry, ok := node.GetReference().(fileEntry); ok && entry.path == current.path {
+			t.tree.SetCurrentNode(node)
+		}
+
+		return true
+	})
+
+	t.updateHelp()
+}
+
+// addChildren adds the entries of the directory into the node. Entries which
+// can not be read are left out, and a warning is shown in their place.
+func (t *fileTree) addChildren(target *tview.TreeNode, path string) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		target.AddChild(warningNode(err))
+	}
+
+	filter := strings.ToLower(t.filter.GetText())
+
+	for _, entry := range entries {
+		if !t.showHidden && isHidden(entry.Name()) {
+			continue
+		}
+
+		childPath := filepath.Join(path, entry.Name())
+
+		file, err := entry.Info()
+		if err != nil {
+			target.AddChild(warningNode(err))
+			continue
+		}
+
+		if file.IsDir() {
+			node := tview.NewTreeNode(t.label(childPath, file.Name()) + fileDetails(file)).
+				SetColor(t.color(childPath, tcell.ColorBlue)).
+				SetReference(fileEntry{path: childPath, dir: true})
+
+			if t.expanded[childPath] {
+				t.addChildren(node, childPath)
+			}
+
+			target.AddChild(node)
+
+			continue
+		}
+
+		if !t.accepts(file.Name()) || !strings.Contains(strings.ToLower(file.Name()), filter) {
+			continue
+		}
+
+		node := tview.NewTreeNode(t.label(childPath, file.Name()) + fileDetails(file)).
+			SetColor(t.color(childPath, tcell.ColorWhite)).
+			SetReference(fileEntry{path: childPath})
+
+		target.AddChild(node)
+	}
+}
+
+// batchable reports whether the marked files can be processed as a batch by
+// the action of the tree.
+func (t *fileTree) batchable() bool {
+	return t.action == actionCompress || t.action == actionDecompress
+}
+
+// accepts reports whether a file with the name can be processed by the
+// action of the tree.
+func (t *fileTree) accepts(name string) bool {
+	return t.action != actionDecompress || filepath.Ext(name) == t.registration.Extension
+}
+
+// label returns the text of the node, escaped so that brackets in the name
+// are not taken as style tags.
+func (t *fileTree) label(path string, name string) string {
+	if t.marked[path] {
+		return tview.Escape("[x] " + name)
+	}
+
+	return tview.Escape(name)
+}
+
+// fileDetails returns the size of a file and its modification time, or only
+// the modification time of a directory, as text shown after its name.
+func fileDetails(file fs.FileInfo) string {
+	modified := file.ModTime().Format(timeLayout)
+
+	if file.IsDir() {
+		return "  [gray]" + modified
+	}
+
+	return fmt.Sprintf("  [gray]%s  %s", progress.FormatSize(int(file.Size())), modified)
+}
+
+// warningNode returns a node showing the error in place of entries which could
+// not be read.
+func warningNode(err error) *tview.TreeNode {
+	return tview.NewTreeNode(tview.Escape("! " + err.Error())).
+		SetColor(tcell.ColorYellow).
+		SetSelectable(false)
+}
+
+func (t *fileTree) color(path string, color tcell.Color) tcell.Color {
+	if t.marked[path] {
+		return tcell.ColorGreen
+	}
+
+	return color
+}
+
+func (t *fileTree) updateHelp() {
+	help := "Enter open/run  Backspace parent  p path  / filter  . hidden  Esc back"
+
+	if t.batchable() {
+		help = fmt.Sprintf("Space mark  r run %d marked  %s", len(t.markedPaths()), help)
+	}
+
+	t.help.SetText(help)
+}
+
+func (t *fileTree) markedPaths() []string {
+	var paths []string
+
+	for path, marked := range t.marked {
+		if marked {
+			paths = append(paths, path)
+		}
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+// markedFiles expands the marked directories into the files in them which the
+// action accepts. Hidden files in marked directories are only included when
+// hidden files are shown.
+func (t *fileTree) markedFiles() ([]string, []batch.Result) {
+	files, failures := batch.Files(t.markedPaths(), true)
+
+	var accepted []string
+
+	seen := map[string]bool{}
+
+	for _, file := range files {
+		if seen[file] || !t.accepts(file) || (!t.showHidden && hasHiddenComponent(t.root, file)) {
+			continue
+		}
+
+		seen[file] = true
+		accepted = append(accepted, file)
+	}
+
+	return accepted, failures
+}
+
+func (t *fileTree) runBatch() {
+	files, failures := t.markedFiles()
+
+	title := "Compressing"
+	if t.action == actionDecompress {
+		title = "Decompressing"
+	}
+
+	t.u.runBatch(title, files, failures, t.action, t.registration)
+}
+
+// parentDirectory returns the parent of the directory, or false if the
+// directory is the root of the file system.
+func parentDirectory(dir string) (string, bool) {
+	parent := filepath.Join(dir, "..")
+
+	absolute, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	absoluteParent, err := filepath.Abs(parent)
+	if err != nil || absoluteParent == absolute {
+		return "", false
+	}
+
+	return parent, true
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+// hasHiddenComponent reports whether any name in the path below the root is
+// hidden.
+func hasHiddenComponent(root string, path string) bool {
+	relative, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+
+	for _, name := range strings.Split(relative, string(filepath.Separator)) {
+		if isHidden(name) {
+			return true
+		}
+	}
+
+	return false
+}
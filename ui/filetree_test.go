@@ -0,0 +1,277 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mjjs/gompressor/codec"
+	"github.com/rivo/tview"
+)
+
+// createFiles creates the files, given as slash separated paths, under the
+// directory.
+func createFiles(t *testing.T, dir string, files []string) {
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMarkedFiles(t *testing.T) {
+	root := t.TempDir()
+	createFiles(t, root, []string{
+		"a.txt",
+		"a.txt.lzw",
+		"dir/b.txt",
+		"dir/b.txt.lzw",
+		"dir/.hidden.lzw",
+		"dir/.cache/c.txt",
+		".config/d.lzw",
+	})
+
+	registration, _ := codec.Lookup("lzw")
+
+	testCases := []struct {
+		name       string
+		action     action
+		showHidden bool
+		marked     []string
+		expected   []string
+	}{
+		{
+			name:     "directory containing a marked file",
+			action:   actionCompress,
+			marked:   []string{"dir", "dir/b.txt"},
+			expected: []string{"dir/b.txt", "dir/b.txt.lzw"},
+		},
+		{
+			name:       "hidden files shown",
+			action:     actionCompress,
+			showHidden: true,
+			marked:     []string{"dir", "dir/b.txt"},
+			expected:   []string{"dir/.cache/c.txt", "dir/.hidden.lzw", "dir/b.txt", "dir/b.txt.lzw"},
+		},
+		{
+			name:     "decompress accepts only the extension",
+			action:   actionDecompress,
+			marked:   []string{"a.txt", "dir"},
+			expected: []string{"dir/b.txt.lzw"},
+		},
+		{
+			name:     "decompress the root",
+			action:   actionDecompress,
+			marked:   []string{"."},
+			expected: []string{"a.txt.lzw", "dir/b.txt.lzw"},
+		},
+		{
+			name:       "decompress the root with hidden files shown",
+			action:     actionDecompress,
+			showHidden: true,
+			marked:     []string{"."},
+			expected:   []string{".config/d.lzw", "a.txt.lzw", "dir/.hidden.lzw", "dir/b.txt.lzw"},
+		},
+		{
+			name:   "hidden directory",
+			action: actionCompress,
+			marked: []string{".config"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		tree := &fileTree{
+			action:       testCase.action,
+			registration: registration,
+			root:         root,
+			showHidden:   testCase.showHidden,
+			marked:       map[string]bool{},
+		}
+
+		for _, path := range testCase.marked {
+			tree.marked[filepath.Join(root, filepath.FromSlash(path))] = true
+		}
+
+		var expected []string
+		for _, path := range testCase.expected {
+			expected = append(expected, filepath.Join(root, filepath.FromSlash(path)))
+		}
+
+		files, failures := tree.markedFiles()
+
+		if !reflect.DeepEqual(expected, files) {
+			t.Errorf("%s: Expected %v, got %v", testCase.name, expected, files)
+		}
+
+		if len(failures) != 0 {
+			t.Errorf("%s: Expected no failures, got %v", testCase.name, failures)
+		}
+	}
+}
+
+func TestMarkedFilesReturnsFailures(t *testing.T) {
+	root := t.TempDir()
+	createFiles(t, root, []string{"a.txt"})
+
+	missing := filepath.Join(root, "missing")
+	tree := &fileTree{
+		action: actionCompress,
+		root:   root,
+		marked: map[string]bool{filepath.Join(root, "a.txt"): true, missing: true},
+	}
+
+	files, failures := tree.markedFiles()
+
+	if expected := []string{filepath.Join(root, "a.txt")}; !reflect.DeepEqual(expected, files) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+
+	if len(failures) != 1 || failures[0].Input != missing {
+		t.Errorf("Expected a failure for %s, got %v", missing, failures)
+	}
+}
+
+func TestHasHiddenComponent(t *testing.T) {
+	testCases := []struct {
+		root     string
+		path     string
+		expected bool
+	}{
+		{root: "/home/user", path: "/home/user/file", expected: false},
+		{root: "/home/user", path: "/home/user/dir/file", expected: false},
+		{root: "/home/user", path: "/home/user/.file", expected: true},
+		{root: "/home/user", path: "/home/user/.dir/file", expected: true},
+		{root: "/home/user", path: "/home/user/dir/.file", expected: true},
+		{root: "/home/.user", path: "/home/.user/file", expected: false},
+		{root: ".", path: "dir/..file", expected: true},
+		{root: "..", path: "../file", expected: false},
+	}
+
+	for _, testCase := range testCases {
+		root := filepath.FromSlash(testCase.root)
+		path := filepath.FromSlash(testCase.path)
+
+		if actual := hasHiddenComponent(root, path); actual != testCase.expected {
+			t.Errorf("%s in %s: Expected %v, got %v", testCase.path, testCase.root, testCase.expected, actual)
+		}
+	}
+}
+
+func TestIsHidden(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{name: ".git", expected: true},
+		{name: ".a.lzw", expected: true},
+		{name: "file.txt", expected: false},
+		{name: ".", expected: false},
+		{name: "..", expected: false},
+	}
+
+	for _, testCase := range testCases {
+		if actual := isHidden(testCase.name); actual != testCase.expected {
+			t.Errorf("%s: Expected %v, got %v", testCase.name, testCase.expected, actual)
+		}
+	}
+}
+
+func TestParentDirectory(t *testing.T) {
+	// Relative directories are resolved against the working directory, which
+	// is changed to one deep enough for their parents not to reach the root.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Chdir(wd)
+
+	testCases := []struct {
+		dir      string
+		expected string
+		ok       bool
+	}{
+		{dir: "/", ok: false},
+		{dir: "/home", expected: "/", ok: true},
+		{dir: "/home/user/", expected: "/home", ok: true},
+		{dir: ".", expected: "..", ok: true},
+		{dir: "dir", expected: ".", ok: true},
+		{dir: "..", expected: "../..", ok: true},
+		{dir: "dir/../other", expected: ".", ok: true},
+		{dir: "dir/sub/..", expected: ".", ok: true},
+	}
+
+	for _, testCase := range testCases {
+		parent, ok := parentDirectory(filepath.FromSlash(testCase.dir))
+
+		if ok != testCase.ok || parent != filepath.FromSlash(testCase.expected) {
+			t.Errorf("%s: Expected %q and %v, got %q and %v", testCase.dir, testCase.expected, testCase.ok, parent, ok)
+		}
+	}
+}
+
+func TestAddChildrenWarnsAboutUnreadableDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any directory")
+	}
+
+	root := t.TempDir()
+	createFiles(t, root, []string{"a.txt", "locked/b.txt"})
+
+	locked := filepath.Join(root, "locked")
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Chmod(locked, 0755)
+
+	tree := &fileTree{
+		action:   actionCompress,
+		root:     root,
+		filter:   tview.NewInputField(),
+		expanded: map[string]bool{root: true, locked: true},
+		marked:   map[string]bool{},
+	}
+
+	testCases := []struct {
+		name string
+		path string
+	}{
+		{name: "root", path: locked},
+		{name: "expanded directory", path: root},
+	}
+
+	for _, testCase := range testCases {
+		node := tview.NewTreeNode(testCase.path)
+		tree.addChildren(node, testCase.path)
+
+		var warnings []*tview.TreeNode
+
+		node.Walk(func(node *tview.TreeNode, parent *tview.TreeNode) bool {
+			if node.GetReference() == nil && strings.HasPrefix(node.GetText(), "! ") {
+				warnings = append(warnings, node)
+			}
+
+			return true
+		})
+
+		if len(warnings) != 1 {
+			t.Errorf("%s: Expected a warning, got %d", testCase.name, len(warnings))
+		} else if warnings[0].GetColor() != tcell.ColorYellow {
+			t.Errorf("%s: Expected %v, got %v", testCase.name, tcell.ColorYellow, warnings[0].GetColor())
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mjjs/gompressor/algorithm/huffman"
+	"github.com/mjjs/gompressor/codec"
+	"github.com/mjjs/gompressor/fileio"
+	"github.com/mjjs/gompressor/progress"
+	"github.com/rivo/tview"
+)
+
+// huffmanTreeView is the code table and the Graphviz DOT graph of a prefix
+// tree.
+type huffmanTreeView struct {
+	compressed bool
+	table      string
+	dot        string
+}
+
+// huffmanTreeJob builds the prefix tree of the file into view. The tree of a
+// file with the extension of Huffman coding is read from the file instead.
+func huffmanTreeJob(filename string, view *huffmanTreeView) jobFunc {
+	return func(ctx context.Context, report progress.Func) (string, error) {
+		data, err := fileio.ReadFile(filename)
+		if err != nil {
+			return "", err
+		}
+
+		registration, _ := codec.LookupAlgorithm(codec.Huffman)
+		view.compressed = filepath.Ext(filename) == registration.Extension
+
+		var tree *huffman.Tree
+
+		if view.compressed {
+			tree, err = huffman.ReadTree(data)
+			if err != nil {
+				return "", err
+			}
+		} else {
+			tree = huffman.BuildTree(data)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		var table, dot strings.Builder
+
+		if err := huffman.WriteCodeTable(&table, tree.Codes(), huffman.FormatText); err != nil {
+			return "", err
+		}
+
+		if err := tree.WriteDOT(&dot); err != nil {
+			return "", err
+		}
+
+		view.table = table.String()
+		view.dot = dot.String()
+
+		return "", nil
+	}
+}
+
+// huffmanTreePanel shows the code table of the tree in a scrollable view.
+// Pressing d switches between the code table and the DOT graph.
+func (u UI) huffmanTreePanel(filename string, view huffmanTreeView) {
+	source := "built from the contents"
+	if view.compressed {
+		source = "read from the compressed file"
+	}
+
+	textView := tview.NewTextView().SetScrollable(true)
+	footer := tview.NewTextView().SetText("Arrows scroll  d code table/DOT graph  Esc back")
+
+	showDOT := false
+	show := func() {
+		text := view.table
+		if showDOT {
+			text = view.dot
+		}
+
+		textView.SetText(fmt.Sprintf("Prefix tree %s\n\n%s", source, text)).ScrollToBeginning()
+	}
+
+	textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'd' {
+			showDOT = !showDOT
+			show()
+
+			return nil
+		}
+
+		return event
+	})
+	textView.SetDoneFunc(func(tcell.Key) {
+		u.application.SetRoot(u.getStartMenu(), true)
+	})
+	textView.SetBorder(true).SetTitle(" Huffman tree of " + tview.Escape(filename) + " ")
+
+	show()
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(textView, 0, 1, true).
+		AddItem(footer, 1, 0, false)
+
+	u.application.SetRoot(layout, true)
+}
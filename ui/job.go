@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mjjs/gompressor/progress"
+	"github.com/rivo/tview"
+)
+
+// progressInterval is the time between redrawing the progress of a job.
+const progressInterval = 100 * time.Millisecond
+
+// jobFunc is the work done by a job. It reports its progress to report, stops
+// when the context is done, and returns the message shown when it succeeds.
+type jobFunc func(ctx context.Context, report progress.Func) (string, error)
+
+// jobView shows the progress of a job running in the background.
+type jobView struct {
+	view  *tview.TextView
+	name  string
+	total int
+	start time.Time
+
+	mu        sync.Mutex
+	in, out   int
+	cancelled bool
+}
+
+// draw updates the text of the view with the latest progress.
+func (v *jobView) draw() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	text := v.name + "\n\n"
+
+	if v.total > 0 {
+		text += fmt.Sprintf("%s %3d%%\n", progress.Bar(v.in, v.total, 40), percent(v.in, v.total))
+	}
+
+	if v.out > 0 {
+		text += fmt.Sprintf("%s -> %s\n", progress.FormatSize(v.in), progress.FormatSize(v.out))
+	}
+
+	text += fmt.Sprintf("Elapsed %s\n\n", formatElapsed(time.Since(v.start)))
+
+	if v.cancelled {
+		text += "Cancelling..."
+	} else {
+		text += "Press Escape to cancel"
+	}
+
+	v.view.SetText(text)
+}
+
+func (v *jobView) report(in int, out int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.in, v.out = in, out
+}
+
+// runJob runs the work in the background, showing its progress until it is
+// done. The total is the amount of input bytes, or zero if the progress can
+// not be measured. Pressing Escape cancels the job. Errors are shown in a
+// modal dialog returning to the main menu.
+func (u UI) runJob(title string, name string, total int, work jobFunc) {
+	u.runJobThen(title, name, total, work, u.messageView)
+}
+
+// runJobThen runs the job like runJob, but shows the message of a successful
+// job with show.
+func (u UI) runJobThen(title string, name string, total int, work jobFunc, show func(message string)) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	v := &jobView{view: tview.NewTextView(), name: name, total: total, start: time.Now()}
+
+	v.view.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			v.mu.Lock()
+			v.cancelled = true
+			v.mu.Unlock()
+
+			cancel()
+			v.draw()
+		}
+	})
+	v.view.SetBorder(true).SetTitle(" " + title + " ")
+	v.draw()
+
+	u.application.SetRoot(v.view, true)
+
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				u.application.QueueUpdateDraw(v.draw)
+			}
+		}
+	}()
+
+	go func() {
+		message, err := work(ctx, v.report)
+		close(done)
+		cancel()
+
+		u.application.QueueUpdateDraw(func() {
+			switch {
+			case errors.Is(err, context.Canceled):
+				u.messageView(fmt.Sprintf("%s %s was cancelled", title, name))
+			case err != nil:
+				u.errorModal(fmt.Sprintf("%s %s failed:\n\n%s", title, name, err))
+			default:
+				show(message)
+			}
+		})
+	}()
+}
+
+// errorModal shows the error message in a modal dialog, which returns to the
+// main menu when closed.
+func (u UI) errorModal(message string) {
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(int, string) {
+			u.application.SetRoot(u.getStartMenu(), true)
+		})
+
+	u.application.SetRoot(modal, true)
+}
+
+// messageView shows the message until a key is pressed, and then returns to
+// the main menu.
+func (u UI) messageView(message string) {
+	textView := tview.NewTextView().SetDoneFunc(func(tcell.Key) {
+		list := u.getStartMenu()
+		u.application.SetRoot(list, true)
+	})
+
+	fmt.Fprintf(textView, "%s\n\nPress any key to return to main menu", message)
+
+	u.application.SetRoot(textView, true)
+}
+
+// percent returns done as a percentage of total.
+func percent(done int, total int) int {
+	if total == 0 {
+		return 100
+	}
+
+	return int(int64(done) * 100 / int64(total))
+}
+
+// formatElapsed formats a duration as minutes and seconds.
+func formatElapsed(d time.Duration) string {
+	seconds := int(d / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mjjs/gompressor/fileio"
+)
+
+// Naming schemes of decompressed files.
+const (
+	// namingSuffix adds .decompressed to the name of the compressed file.
+	namingSuffix = "suffix"
+	// namingStrip removes the extension of the algorithm from the name.
+	namingStrip = "strip"
+)
+
+// Overwrite behaviours of existing output files.
+const (
+	overwriteAlways = "always"
+	overwriteNever  = "never"
+	// overwriteRename writes into a new file numbered after the existing one.
+	overwriteRename = "rename"
+)
+
+var (
+	dictionarySizes = []string{"xs", "s", "m", "l", "xl"}
+	namings         = []string{namingSuffix, namingStrip}
+	overwrites      = []string{overwriteAlways, overwriteNever, overwriteRename}
+)
+
+// errInvalidSettings is returned when a configuration file contains invalid
+// settings.
+var errInvalidSettings = errors.New("invalid settings")
+
+// errOutputExists is returned when the output file exists and existing files
+// are never overwritten.
+var errOutputExists = errors.New("output file already exists")
+
+// settings are the user's choices persisted between runs of the interface.
+type settings struct {
+	DictionarySize string `json:"dictionarySize"`
+	// OutputDirectory is the directory of the output files. An empty
+	// directory writes the output next to the input file.
+	OutputDirectory string `json:"outputDirectory"`
+	Naming          string `json:"naming"`
+	Overwrite       string `json:"overwrite"`
+	Verify          bool   `json:"verify"`
+}
+
+func defaultSettings() settings {
+	return settings{
+		DictionarySize: "xl",
+		Naming:         namingSuffix,
+		Overwrite:      overwriteAlways,
+	}
+}
+
+// settingsPath returns the path of the configuration file in the user's
+// configuration directory.
+func settingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "gompressor", "settings.json"), nil
+}
+
+// loadSettings reads the settings from the file. A missing file gives the
+// default settings, and settings missing from the file keep their defaults.
+func loadSettings(filename string) (settings, error) {
+	s := defaultSettings()
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return defaultSettings(), fmt.Errorf("%w: %s", errInvalidSettings, err)
+	}
+
+	if err := s.validate(); err != nil {
+		return defaultSettings(), err
+	}
+
+	return s, nil
+}
+
+// save writes the settings into the file, creating its directory if needed.
+func (s settings) save(filename string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	file, err := fileio.CreateAtomic(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	return file.Commit()
+}
+
+func (s settings) validate() error {
+	if !contains(dictionarySizes, s.DictionarySize) {
+		return fmt.Errorf("%w: dictionary size %q", errInvalidSettings, s.DictionarySize)
+	}
+
+	if !contains(namings, s.Naming) {
+		return fmt.Errorf("%w: naming %q", errInvalidSettings, s.Naming)
+	}
+
+	if !contains(overwrites, s.Overwrite) {
+		return fmt.Errorf("%w: overwrite %q", errInvalidSettings, s.Overwrite)
+	}
+
+	return nil
+}
+
+// compressedName returns the name of the file compressed into a file with the
+// extension.
+func (s settings) compressedName(filename string, extension string) string {
+	return s.inOutputDirectory(filename + extension)
+}
+
+// decompressedName returns the name of the file decompressed from a file with
+// the extension.
+func (s settings) decompressedName(filename string, extension string) string {
+	base := filepath.Base(filename)
+
+	if s.Naming == namingStrip && strings.HasSuffix(base, extension) && len(base) > len(extension) {
+		return s.inOutputDirectory(strings.TrimSuffix(filename, extension))
+	}
+
+	return s.inOutputDirectory(filename + ".decompressed")
+}
+
+func (s settings) inOutputDirectory(filename string) string {
+	if s.OutputDirectory == "" {
+		return filename
+	}
+
+	return filepath.Join(s.OutputDirectory, filepath.Base(filename))
+}
+
+// resolveOutput applies the overwrite behaviour to the output file, returning
+// the name of the file to write into.
+func (s settings) resolveOutput(filename string) (string, error) {
+	if _, err := os.Lstat(filename); errors.Is(err, os.ErrNotExist) {
+		return filename, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	switch s.Overwrite {
+	case overwriteNever:
+		return "", fmt.Errorf("%w: %s", errOutputExists, filename)
+	case overwriteRename:
+		return numberedName(filename)
+	default:
+		return filename, nil
+	}
+}
+
+// numberedName returns the first name which does not exist, numbered before
+// the extension of the file, such as file-1.txt for file.txt.
+func numberedName(filename string) (string, error) {
+	extension := filepath.Ext(filename)
+	stem := strings.TrimSuffix(filename, extension)
+
+	for n := 1; ; n++ {
+		candidate := stem + "-" + strconv.Itoa(n) + extension
+
+		_, err := os.Lstat(candidate)
+		if errors.Is(err, os.ErrNotExist) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+
+	return 0
+}
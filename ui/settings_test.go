@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSettingsRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "gompressor", "settings.json")
+
+	s, err := loadSettings(filename)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if s != defaultSettings() {
+		t.Errorf("Expected %+v, got %+v", defaultSettings(), s)
+	}
+
+	expected := settings{
+		DictionarySize:  "m",
+		OutputDirectory: "/tmp/out",
+		Naming:          namingStrip,
+		Overwrite:       overwriteRename,
+		Verify:          true,
+	}
+
+	if err := expected.save(filename); err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	actual, err := loadSettings(filename)
+	if err != nil {
+		t.Fatalf("Expected nil error, got %s", err)
+	}
+
+	if actual != expected {
+		t.Errorf("Expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestLoadSettingsInvalid(t *testing.T) {
+	testCases := []string{
+		"not json",
+		`{"dictionarySize": "huge"}`,
+		`{"naming": "random"}`,
+		`{"overwrite": "sometimes"}`,
+	}
+
+	for _, testCase := range testCases {
+		filename := filepath.Join(t.TempDir(), "settings.json")
+		if err := os.WriteFile(filename, []byte(testCase), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := loadSettings(filename)
+		if !errors.Is(err, errInvalidSettings) {
+			t.Errorf("%s: Expected %s, got %v", testCase, errInvalidSettings, err)
+		}
+
+		if s != defaultSettings() {
+			t.Errorf("%s: Expected %+v, got %+v", testCase, defaultSettings(), s)
+		}
+	}
+}
+
+func TestOutputNames(t *testing.T) {
+	testCases := []struct {
+		settings     settings
+		decompress   bool
+		input        string
+		expectedName string
+	}{
+		{settings: settings{}, input: "dir/file.txt", expectedName: "dir/file.txt.lzw"},
+		{settings: settings{OutputDirectory: "out"}, input: "dir/file.txt", expectedName: "out/file.txt.lzw"},
+		{settings: settings{Naming: namingSuffix}, decompress: true, input: "dir/file.txt.lzw", expectedName: "dir/file.txt.lzw.decompressed"},
+		{settings: settings{Naming: namingStrip}, decompress: true, input: "dir/file.txt.lzw", expectedName: "dir/file.txt"},
+		{settings: settings{Naming: namingStrip}, decompress: true, input: "dir/.lzw", expectedName: "dir/.lzw.decompressed"},
+		{settings: settings{Naming: namingStrip, OutputDirectory: "out"}, decompress: true, input: "dir/file.txt.lzw", expectedName: "out/file.txt"},
+	}
+
+	for _, testCase := range testCases {
+		var actual string
+		if testCase.decompress {
+			actual = testCase.settings.decompressedName(testCase.input, ".lzw")
+		} else {
+			actual = testCase.settings.compressedName(testCase.input, ".lzw")
+		}
+
+		if actual != testCase.expectedName {
+			t.Errorf("Expected %s, got %s", testCase.expectedName, actual)
+		}
+	}
+}
+
+func TestResolveOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	existing := filepath.Join(dir, "file.txt.lzw")
+	for _, name := range []string{existing, filepath.Join(dir, "file.txt-1.lzw")} {
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	missing := filepath.Join(dir, "other.lzw")
+
+	testCases := []struct {
+		overwrite    string
+		filename     string
+		expectedName string
+		expectedErr  error
+	}{
+		{overwrite: overwriteAlways, filename: missing, expectedName: missing},
+		{overwrite: overwriteNever, filename: missing, expectedName: missing},
+		{overwrite: overwriteAlways, filename: existing, expectedName: existing},
+		{overwrite: overwriteNever, filename: existing, expectedErr: errOutputExists},
+		{overwrite: overwriteRename, filename: existing, expectedName: filepath.Join(dir, "file.txt-2.lzw")},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := settings{Overwrite: testCase.overwrite}.resolveOutput(testCase.filename)
+
+		if !errors.Is(err, testCase.expectedErr) {
+			t.Errorf("Expected %v, got %v", testCase.expectedErr, err)
+		}
+
+		if actual != testCase.expectedName {
+			t.Errorf("Expected %s, got %s", testCase.expectedName, actual)
+		}
+	}
+}
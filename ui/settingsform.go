@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rivo/tview"
+)
+
+// Labels of the choices shown in the settings form, in the order of the
+// choices.
+var (
+	dictionarySizeLabels = []string{"xs (512)", "s (1023)", "m (4095)", "l (32767)", "xl (65535)"}
+	namingLabels         = []string{"Add .decompressed", "Remove the extension"}
+	overwriteLabels      = []string{"Overwrite", "Never overwrite", "Write into a numbered file"}
+)
+
+// settingsForm shows the settings in a form, which saves them into the
+// configuration file.
+func (u UI) settingsForm() {
+	edited := *u.settings
+
+	form := tview.NewForm().
+		AddDropDown("LZW dictionary size", dictionarySizeLabels, indexOf(dictionarySizes, edited.DictionarySize), func(_ string, i int) {
+			edited.DictionarySize = dictionarySizes[i]
+		}).
+		AddInputField("Output directory", edited.OutputDirectory, 40, nil, func(text string) {
+			edited.OutputDirectory = text
+		}).
+		AddDropDown("Decompressed file name", namingLabels, indexOf(namings, edited.Naming), func(_ string, i int) {
+			edited.Naming = namings[i]
+		}).
+		AddDropDown("Existing output files", overwriteLabels, indexOf(overwrites, edited.Overwrite), func(_ string, i int) {
+			edited.Overwrite = overwrites[i]
+		}).
+		AddCheckbox("Verify after compressing", edited.Verify, func(checked bool) {
+			edited.Verify = checked
+		})
+
+	form.AddButton("Save", func() {
+		if err := u.saveSettings(edited); err != nil {
+			u.errorModal(fmt.Sprintf("Saving the settings failed:\n\n%s", err))
+			return
+		}
+
+		u.messageView(fmt.Sprintf("Saved the settings to %s", u.settingsPath))
+	})
+
+	form.AddButton("Cancel", func() {
+		u.application.SetRoot(u.getStartMenu(), true)
+	})
+
+	form.SetCancelFunc(func() {
+		u.application.SetRoot(u.getStartMenu(), true)
+	})
+
+	form.SetBorder(true).SetTitle(" Settings ")
+	u.application.SetRoot(form, true)
+}
+
+// saveSettings writes the settings into the configuration file and uses them
+// for the following jobs.
+func (u UI) saveSettings(s settings) error {
+	if s.OutputDirectory != "" {
+		info, err := os.Stat(s.OutputDirectory)
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return fmt.Errorf("%w: %s is not a directory", errInvalidSettings, s.OutputDirectory)
+		}
+	}
+
+	if u.settingsPath == "" {
+		return fmt.Errorf("no configuration directory: %w", u.settingsErr)
+	}
+
+	if err := s.save(u.settingsPath); err != nil {
+		return err
+	}
+
+	*u.settings = s
+
+	return nil
+}
@@ -1,221 +1,291 @@
 package ui
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"path/filepath"
+	"unicode"
 
-	"github.com/gdamore/tcell/v2"
-	"github.com/mjjs/gompressor/algorithm/huffman"
-	"github.com/mjjs/gompressor/algorithm/lzw"
-	"github.com/mjjs/gompressor/datastructure/vector"
+	"github.com/mjjs/gompressor/batch"
+	"github.com/mjjs/gompressor/benchmark"
+	"github.com/mjjs/gompressor/codec"
 	"github.com/mjjs/gompressor/fileio"
+	"github.com/mjjs/gompressor/limit"
+	"github.com/mjjs/gompressor/progress"
 	"github.com/rivo/tview"
 )
 
-type algorithm int
-
-const (
-	algorithmLZW algorithm = iota
-	algorithmHuffman
-)
+// errVerificationFailed is returned when compressed data does not decompress
+// into the original data.
+var errVerificationFailed = errors.New("verification failed")
 
 type action int
 
 const (
 	actionCompress action = iota
 	actionDecompress
+	actionCompare
+	actionHuffmanTree
 )
 
 // UI handles running the user interface
 type UI struct {
 	application *tview.Application
+	// settings and directory are shared by the copies of the UI in the
+	// callbacks, so that changes to them apply to the following jobs.
+	settings     *settings
+	settingsPath string
+	settingsErr  error
+	// directory is the root directory of the file tree.
+	directory *string
 }
 
 func New() *UI {
+	s := defaultSettings()
+
+	path, err := settingsPath()
+	if err == nil {
+		s, err = loadSettings(path)
+	}
+
+	directory := "."
+
 	return &UI{
-		application: tview.NewApplication(),
+		application:  tview.NewApplication(),
+		settings:     &s,
+		settingsPath: path,
+		settingsErr:  err,
+		directory:    &directory,
 	}
 }
 
+// SetDirectory sets the directory the file tree starts from.
+func (u *UI) SetDirectory(dir string) *UI {
+	*u.directory = dir
+	return u
+}
+
 // Run starts the user interface main loop
 func (u UI) Run() {
 	list := u.getStartMenu()
+	u.application.SetRoot(list, true).SetFocus(list)
 
-	err := u.application.SetRoot(list, true).SetFocus(list).Run()
-	if err != nil {
+	if u.settingsErr != nil {
+		u.errorModal(fmt.Sprintf("Could not load the settings, using the defaults:\n\n%s", u.settingsErr))
+	}
+
+	if err := u.application.Run(); err != nil {
 		panic(err)
 	}
 }
 
 func (u UI) getStartMenu() *tview.List {
 	list := tview.NewList().
-		AddItem("Compress", "Compress a file", 'c', u.algorithmSelect(actionCompress)).
-		AddItem("Decompress", "Decompress a file", 'd', u.algorithmSelect(actionDecompress)).
+		AddItem("Compress", "Compress files", 'c', u.algorithmSelect(actionCompress)).
+		AddItem("Decompress", "Decompress files", 'd', u.algorithmSelect(actionDecompress)).
+		AddItem("Huffman tree", "Show the Huffman codes of a file", 'h', u.fileSelect(actionHuffmanTree, codec.Registration{})).
+		AddItem("Settings", "Choose the dictionary size and output files", 's', u.settingsForm).
 		AddItem("Quit", "Exit the application", 'q', u.application.Stop)
 
 	return list
 }
 
-func (u UI) fileSelect(action action, algorithm algorithm) func() {
+func (u UI) algorithmSelect(action action) func() {
 	return func() {
-		rootDir := "."
-		root := tview.NewTreeNode(rootDir).SetColor(tcell.ColorRed)
-		tree := tview.NewTreeView().SetRoot(root).SetCurrentNode(root)
+		list := tview.NewList()
+
+		for _, r := range codec.Registrations() {
+			list.AddItem(r.Name, r.Description, unicode.ToLower(rune(r.Name[0])), u.fileSelect(action, r))
+		}
 
 		if action == actionCompress {
-			u.buildFileTree(root, rootDir, action, algorithm)
-		} else if algorithm == algorithmHuffman {
-			u.buildFileTree(root, rootDir, action, algorithm)
-		} else if algorithm == algorithmLZW {
-			u.buildFileTree(root, rootDir, action, algorithm)
-		} else {
-			panic("This should not happen!")
+			list.AddItem("Compare", "Compare every algorithm on a file", 'a', u.fileSelect(actionCompare, codec.Registration{}))
 		}
 
-		u.application.SetRoot(tree, true)
+		u.application.SetRoot(list, true)
 	}
 }
 
-func (u UI) algorithmSelect(action action) func() {
+func (u UI) selectFile(filepath string, action action, registration codec.Registration) func() {
 	return func() {
-		list := tview.NewList().
-			AddItem("Huffman", "Huffman coding", 'h', u.fileSelect(action, algorithmHuffman)).
-			AddItem("LZW", "Lempel-Ziv-Welch", 'l', u.fileSelect(action, algorithmLZW))
+		total := 0
+		if info, err := os.Stat(filepath); err == nil {
+			total = int(info.Size())
+		}
 
-		u.application.SetRoot(list, true)
+		if action == actionCompress {
+			u.runJob("Compressing", filepath, total, compressJob(filepath, registration, *u.settings))
+			return
+		}
+
+		if action == actionHuffmanTree {
+			var tree huffmanTreeView
+
+			u.runJobThen("Building tree", filepath, 0, huffmanTreeJob(filepath, &tree), func(string) {
+				u.huffmanTreePanel(filepath, tree)
+			})
+			return
+		}
+
+		if action == actionCompare {
+			candidates, err := benchmark.Candidates()
+			if err != nil {
+				u.errorModal(err.Error())
+				return
+			}
+
+			u.runJob("Comparing", filepath, total*len(candidates), compareJob(filepath, candidates))
+			return
+		}
+
+		// Decompression does not report its progress.
+		u.runJob("Decompressing", filepath, 0, decompressJob(filepath, registration, *u.settings))
 	}
 }
 
-func (u UI) buildFileTree(target *tview.TreeNode, path string, action action, algorithm algorithm) {
-	files, err := ioutil.ReadDir(path)
-	if err != nil {
-		panic(err)
+// newCodec creates the codec of the registration with the options chosen in
+// the settings.
+func newCodec(registration codec.Registration, s settings) (codec.Codec, error) {
+	options := map[string]string{}
+
+	for _, option := range registration.Options {
+		if option.Name == "dict-size" {
+			options[option.Name] = s.DictionarySize
+		}
 	}
 
-	for _, file := range files {
-		node := tview.NewTreeNode(file.Name()).
-			SetSelectable(true)
+	return codec.New(registration.Name, options)
+}
 
-		if file.IsDir() {
-			node.SetColor(tcell.ColorBlue)
-			node.SetSelectedFunc(u.selectDir(node, path, file, action, algorithm))
-		} else {
-			node.SetSelectedFunc(u.selectFile(filepath.Join(path, file.Name()), action, algorithm))
+// compressJob compresses the file, and verifies the compressed data if chosen
+// in the settings.
+func compressJob(filename string, registration codec.Registration, s settings) jobFunc {
+	return func(ctx context.Context, report progress.Func) (string, error) {
+		result := compressFile(ctx, filename, registration, s, report)
+		if result.Err != nil {
+			return "", result.Err
 		}
 
-		ext := algorithmToExtension(algorithm)
-
-		if file.IsDir() || action == actionCompress || ext == filepath.Ext(file.Name()) {
-			target.AddChild(node)
+		if s.Verify {
+			return fmt.Sprintf("Wrote to file %s and verified it", result.Output), nil
 		}
+
+		return fmt.Sprintf("Wrote to file %s", result.Output), nil
 	}
 }
 
-func (u UI) selectDir(node *tview.TreeNode, path string, file os.FileInfo, action action, algorithm algorithm) func() {
-	return func() {
-		children := node.GetChildren()
-
-		if len(children) == 0 {
-			u.buildFileTree(node, filepath.Join(path, file.Name()), action, algorithm)
-		} else {
-			node.SetExpanded(!node.IsExpanded())
+// decompressJob decompresses the file. The decompression stops when the job is
+// cancelled, and a cancelled job does not write the output file.
+func decompressJob(filename string, registration codec.Registration, s settings) jobFunc {
+	return func(ctx context.Context, report progress.Func) (string, error) {
+		result := decompressFile(ctx, filename, registration, s)
+		if result.Err != nil {
+			return "", result.Err
 		}
+
+		return fmt.Sprintf("Wrote to file %s", result.Output), nil
 	}
 }
 
-func (u UI) selectFile(filepath string, action action, algorithm algorithm) func() {
-	return func() {
-		var outFilename string
+// compressFile compresses the file into a file with the extension of the
+// algorithm added to its name, and verifies the compressed data if chosen in
+// the settings.
+func compressFile(ctx context.Context, filename string, registration codec.Registration, s settings, report progress.Func) batch.Result {
+	result := batch.Result{Input: filename}
 
-		if action == actionCompress {
-			outFilename = fmt.Sprintf("%s.lzw", filepath)
-			if algorithm == algorithmHuffman {
-				outFilename = fmt.Sprintf("%s.huff", filepath)
-			}
+	c, err := newCodec(registration, s)
+	if err != nil {
+		result.Err = err
+		return result
+	}
 
-			bytes, err := fileio.ReadFile(filepath)
-			if err != nil {
-				panic(err)
-			}
+	result.Output, result.Err = s.resolveOutput(s.compressedName(filename, registration.Extension))
+	if result.Err != nil {
+		return result
+	}
 
-			var compressed *vector.Vector
-
-			if algorithm == algorithmLZW {
-				compressed, err = lzw.Compress(bytes)
-				if err != nil {
-					panic(err)
-				}
-
-				err = fileio.WriteLZWFile(compressed, outFilename)
-				if err != nil {
-					panic(err)
-				}
-			} else {
-				compressed = huffman.Compress(bytes)
-				err = fileio.WriteFile(compressed, outFilename)
-				if err != nil {
-					panic(err)
-				}
-			}
-		} else {
-			outFilename = fmt.Sprintf("%s.decompressed", filepath)
-			if algorithm == algorithmHuffman {
-				outFilename = fmt.Sprintf("%s.decompressed", filepath)
-			}
+	data, err := fileio.ReadFile(filename)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.InputSize = data.Size()
+
+	compressed, err := codec.CompressContext(ctx, c, data, report)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.OutputSize = compressed.Size()
 
-			if algorithm == algorithmLZW {
-				codes, err := fileio.ReadLZWFile(filepath)
-				if err != nil {
-					panic(err)
-				}
-
-				decompressed, err := lzw.Decompress(codes)
-				if err != nil {
-					panic(err)
-				}
-
-				err = fileio.WriteFile(decompressed, outFilename)
-				if err != nil {
-					panic(err)
-				}
-			} else {
-				bytes, err := fileio.ReadFile(filepath)
-				if err != nil {
-					panic(err)
-				}
-
-				decompressed, err := huffman.Decompress(bytes)
-				if err != nil {
-					panic(err)
-				}
-
-				err = fileio.WriteFile(decompressed, outFilename)
-				if err != nil {
-					panic(err)
-				}
+	if s.Verify {
+		decompressed, err := codec.DecompressContext(ctx, c, compressed, limit.Limits{MaxOutput: data.Size()})
+		if err != nil {
+			// A cancelled verification is reported as cancelled, not as
+			// failed.
+			if result.Err = ctx.Err(); result.Err == nil {
+				result.Err = fmt.Errorf("%w: %s", errVerificationFailed, err)
 			}
+
+			return result
 		}
 
-		u.fileWrittenView(outFilename)
+		if !bytes.Equal(decompressed.Slice(), data.Slice()) {
+			result.Err = fmt.Errorf("%w: decompressed data differs from the original", errVerificationFailed)
+			return result
+		}
 	}
-}
 
-func (u UI) fileWrittenView(filename string) {
-	textView := tview.NewTextView().SetDoneFunc(func(tcell.Key) {
-		list := u.getStartMenu()
-		u.application.SetRoot(list, true)
-	})
+	if result.Err = ctx.Err(); result.Err != nil {
+		return result
+	}
 
-	fmt.Fprintf(textView, "Wrote to file %s\n\nPress any key to return to main menu", filename)
+	result.Err = fileio.WriteFile(compressed, result.Output)
 
-	u.application.SetRoot(textView, true)
+	return result
 }
 
-func algorithmToExtension(a algorithm) string {
-	if a == algorithmHuffman {
-		return ".huff"
+// decompressFile decompresses the file into a file named as chosen in the
+// settings. The decompression stops when the context is done.
+func decompressFile(ctx context.Context, filename string, registration codec.Registration, s settings) batch.Result {
+	result := batch.Result{Input: filename}
+
+	c, err := registration.New(nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Output, result.Err = s.resolveOutput(s.decompressedName(filename, registration.Extension))
+	if result.Err != nil {
+		return result
+	}
+
+	data, err := fileio.ReadFile(filename)
+	if err != nil {
+		result.Err = err
+		return result
 	}
-	return ".lzw"
+
+	result.InputSize = data.Size()
+
+	decompressed, err := codec.DecompressContext(ctx, c, data, limit.Limits{})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.OutputSize = decompressed.Size()
+
+	if result.Err = ctx.Err(); result.Err != nil {
+		return result
+	}
+
+	result.Err = fileio.WriteFile(decompressed, result.Output)
+
+	return result
 }
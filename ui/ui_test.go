@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mjjs/gompressor/codec"
+)
+
+func TestDecompressFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "file.txt")
+	contents := bytes.Repeat([]byte("TOBEORNOT"), 100)
+
+	if err := os.WriteFile(input, contents, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registration, _ := codec.Lookup("lzw")
+	s := defaultSettings()
+
+	compressed := compressFile(context.Background(), input, registration, s, nil)
+	if compressed.Err != nil {
+		t.Fatalf("Expected nil error, got %s", compressed.Err)
+	}
+
+	output := s.decompressedName(compressed.Output, registration.Extension)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if result := decompressFile(cancelled, compressed.Output, registration, s); !errors.Is(result.Err, context.Canceled) {
+		t.Errorf("Expected %s, got %v", context.Canceled, result.Err)
+	}
+
+	if _, err := os.Stat(output); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected no output file, got %v", err)
+	}
+
+	if result := decompressFile(context.Background(), compressed.Output, registration, s); result.Err != nil {
+		t.Fatalf("Expected nil error, got %s", result.Err)
+	}
+
+	decompressed, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(contents, decompressed) {
+		t.Errorf("Expected %q, got %q", contents, decompressed)
+	}
+}
+
+func TestCompressFileCancelledDuringVerification(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "file.txt")
+	contents := bytes.Repeat([]byte("TOBEORNOT"), 100)
+
+	if err := os.WriteFile(input, contents, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registration, _ := codec.Lookup("huffman")
+	s := defaultSettings()
+	s.Verify = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The job is cancelled once all of the input has been compressed, so
+	// that the cancellation happens during the verification.
+	result := compressFile(ctx, input, registration, s, func(read int, written int) {
+		if read == len(contents) {
+			cancel()
+		}
+	})
+
+	if !errors.Is(result.Err, context.Canceled) {
+		t.Errorf("Expected %s, got %v", context.Canceled, result.Err)
+	}
+
+	if errors.Is(result.Err, errVerificationFailed) {
+		t.Errorf("Expected the verification not to fail, got %s", result.Err)
+	}
+
+	if _, err := os.Stat(result.Output); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected no output file, got %v", err)
+	}
+}
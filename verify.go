@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mjjs/gompressor/batch"
+)
+
+func runTest(name string, args []string) int {
+	flags := newFlagSet(name)
+	codecFlags := addCodecFlags(flags, "")
+	threadsFlag := flags.Int("threads", 0, "number of threads used for decompressing blocks, 0 uses all CPUs")
+	jobsFlag := flags.Int("jobs", 0, "number of files processed concurrently, 0 uses all CPUs")
+	recursiveFlag := flags.Bool("r", false, "process the files in the given directories recursively")
+	mmapFlag := flags.Bool("mmap", false, "map the files into memory instead of reading them, if possible")
+	limitFlags := addLimitFlags(flags)
+	flags.Parse(args)
+
+	c, err := codecFlags.codec()
+	if err != nil {
+		return fail(err)
+	}
+
+	limits, err := limitFlags.limits()
+	if err != nil {
+		return fail(err)
+	}
+
+	opts := options{codec: c, threads: *threadsFlag, length: -1, mmap: *mmapFlag, limits: limits}
+
+	return testFiles(inputFiles(flags.Args()), *recursiveFlag, *jobsFlag, opts)
+}
+
+// testFiles tests the integrity of the files concurrently, and prints OK or
+// FAIL for each of them into the standard error. testFiles returns the exit
+// code of the command, which is 1 if any of the files failed.
+func testFiles(paths []string, recursive bool, jobs int, opts options) int {
+	results := runFiles(paths, recursive, jobs, func(file string) batch.Result {
+		result := batch.Result{Input: file}
+		result.InputSize, result.OutputSize, result.Err = testFile(file, opts)
+
+		return result
+	})
+
+	failed := 0
+
+	for _, result := range results {
+		name := displayName(result.Input, true)
+
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s: FAIL: %s\n", name, result.Err)
+			failed++
+
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "%s: OK (%d -> %d bytes)\n", name, result.InputSize, result.OutputSize)
+	}
+
+	if len(results) > 1 {
+		fmt.Fprintf(os.Stderr, "%d of %d files OK\n", len(results)-failed, len(results))
+	}
+
+	if failed > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// testFile decompresses the file in memory, and checks that the size of the
+// decompressed data matches the original size stored in the headers of the
+// block and seekable formats. testFile returns the compressed and the
+// decompressed size.
+func testFile(filename string, opts options) (int, int, error) {
+	data, release, err := loadInput(filename, opts.mmap)
+	if err != nil {
+		return 0, 0, fmt.Errorf("input file could not be read: %w", err)
+	}
+
+	defer release()
+
+	if opts.codec == nil && filename != stdio {
+		opts.codec, _ = codecForFile(filename)
+	}
+
+	info, err := readInfo(data, opts.codec)
+	if err != nil {
+		return data.Size(), 0, fmt.Errorf("could not read headers: %w", err)
+	}
+
+	decompressed, err := decompress(data, opts)
+	if err != nil {
+		return data.Size(), 0, fmt.Errorf("could not decompress data: %w", err)
+	}
+
+	if info.originalSize >= 0 && decompressed.Size() != info.originalSize {
+		return data.Size(), decompressed.Size(), fmt.Errorf("decompressed into %d bytes, expected %d",
+			decompressed.Size(), info.originalSize)
+	}
+
+	return data.Size(), decompressed.Size(), nil
+}